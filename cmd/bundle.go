@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/bundle"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var bundleConfig markdown.Config
+var bundleOutput string
+
+func init() {
+	flags := bundleCmd.Flags()
+	flags.StringVarP(&bundleOutput, "output", "f", "", "the output file, the bundle is printed when omitted")
+	flags.StringVarP(&bundleConfig.Extension, "extension", "e", "*.schema.json", "the schema extension")
+	flags.BoolVarP(&bundleConfig.Recursive, "walk", "w", false, "walk through sub-directories")
+	flags.BoolVarP(&bundleConfig.Ordered, "ordered", "o", false, "preserve the schema order (defaults to alphabetical)")
+	rootCmd.AddCommand(bundleCmd)
+}
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle [root-schema]",
+	Short: "bundle [root-schema]",
+	Long:  "Inlines the external references of the root schema into its $defs, producing a single self-contained schema.",
+	Args:  validatePaths(),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := markdown.NewConverter(bundleConfig)
+		b := bundle.NewBundler(c, bundleConfig.Ordered)
+		bundled, err := b.Bundle(args[0])
+		if err != nil {
+			reportError(err, "text")
+		}
+		if err = b.Validate(bundled); err != nil {
+			reportError(err, "text")
+		}
+
+		content, err := json.MarshalIndent(bundled, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(bundleOutput) == 0 {
+			_, err = os.Stdout.Write(append(content, '\n'))
+		} else {
+			err = afero.WriteFile(markdown.AppFS, bundleOutput, append(content, '\n'), os.ModePerm)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"go/token"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/codegen"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/cobra"
+)
+
+var codegenConfig markdown.Config
+var codegenLang string
+var codegenPackage string
+
+func init() {
+	flags := codegenCmd.Flags()
+	flags.StringVarP(&codegenConfig.Destination, "destination", "d", ".", "the output directory of the generated files")
+	flags.StringVarP(&codegenConfig.Extension, "extension", "e", "*.schema.json", "the schema extension")
+	flags.BoolVarP(&codegenConfig.Recursive, "walk", "w", false, "walk through sub-directories")
+	flags.StringVar(&codegenLang, "lang", "ts", "the language of the generated types: go or ts")
+	flags.StringVar(&codegenPackage, "package", "", "the package of the generated go files (defaults to the name of the destination directory)")
+	rootCmd.AddCommand(codegenCmd)
+}
+
+var codegenCmd = &cobra.Command{
+	Use:   "codegen [path]",
+	Short: "codegen [path]",
+	Long:  "Generates type definitions from the schemas, one file per schema file, importing the types referenced across files.",
+	Args:  validatePaths(),
+	Run: func(cmd *cobra.Command, args []string) {
+		lang, err := codegen.FindLanguage(codegenLang)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if _, ok := lang.(codegen.Golang); ok {
+			lang = codegen.Golang{Package: markdown.FirstNonEmpty(codegenPackage, goPackage(codegenConfig.Destination))}
+		}
+
+		c := markdown.NewConverter(codegenConfig)
+		schemas, err := c.Load(args[0])
+		if err != nil {
+			reportError(err, "text")
+		}
+		writeGenerated(lang, schemas, codegenConfig.Destination, "generated")
+	},
+}
+
+// goPackage returns the package named after the directory dir, if it is a valid identifier
+func goPackage(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	name := strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(filepath.Base(abs)))
+	if len(name) == 0 || !token.IsIdentifier(name) {
+		return ""
+	}
+	return name
+}
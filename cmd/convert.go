@@ -5,12 +5,20 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"runtime"
+	"strings"
 
 	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
 	"github.com/spf13/cobra"
 )
 
 var config markdown.Config
+var errorFormat string
+
+// errorFormats are the formats the problems of a failed conversion are reported in
+var errorFormats = []string{"text", "json"}
+var frontMatterFile string
+var frontMatterFormat string
 
 func init() {
 	flags := convert.Flags()
@@ -19,7 +27,24 @@ func init() {
 	flags.BoolVarP(&config.Recursive, "walk", "w", false, "walk through sub-directories")
 	flags.BoolVarP(&config.Ordered, "ordered", "o", false, "preserve the schema order (defaults to alphabetical)")
 	flags.BoolVarP(&config.OrderedFilePath, "orderedfilepath", "p", false, "preserve the schema order (defaults to alphabetical) by appending a digit to the filename prefix")
-	flags.BoolVarP(&config.Clean, "clean", "c", false, "removes the output directory before generating output files")
+	flags.BoolVarP(&config.Clean, "clean", "c", false, "removes the files generated by the previous run, as listed by the manifest, before generating output files")
+	flags.IntVar(&config.InlineDepth, "inline-depth", 0, "the depth from which nested objects are rendered in tables of their own (0 inlines every depth)")
+	flags.BoolVar(&config.Collapse, "collapse", false, "render the tables of nested objects as collapsible details blocks")
+	flags.BoolVar(&config.FlattenAllOf, "flatten-allof", false, "merge allOf compositions, following $refs, into a single properties table annotated with the branch of each property")
+	flags.StringVar(&config.Draft, "draft", "", "the draft of the schemas lacking $schema: 4, 6, 7, 2019-09 or 2020-12 (defaults to 2020-12)")
+	flags.StringToStringVar(&config.Formats, "custom-format", nil, "a custom format validated by a regular expression, e.g. sku=^[A-Z]{3}-[0-9]+$")
+	flags.StringSliceVar(&config.Keywords, "keyword", nil, "a custom keyword rendered in the restrictions column by the keyword-<name> template, or as its value")
+	flags.StringVar(&config.Regex, "regex", markdown.RegexECMAScript, "the engine enforcing the patterns the go regexp package rejects, e.g. lookarounds: ecmascript or go")
+	flags.StringVar(&config.Templates, "templates", "", "a directory of gohtml templates overriding the built-in templates of the same name, e.g. index.gohtml")
+	flags.StringVar(&config.Links, "links", markdown.LinksBaseURL, "how pages link to each other: baseurl, relref (hugo relref shortcodes) or relative (plain markdown links)")
+	flags.BoolVar(&config.SinglePage, "single-page", false, "render each schema as a single page with its definitions as sections")
+	flags.BoolVar(&config.Samples, "samples", false, "add an example payload section with a minimal and a full sample instance to each page")
+	flags.BoolVar(&config.Prune, "prune", true, "removes previously generated files that are no longer produced, leaving other files untouched")
+	flags.BoolVar(&config.Cache, "cache", true, "keep a content-hash cache in the output directory and only re-render changed pages")
+	flags.IntVarP(&config.Jobs, "jobs", "j", runtime.NumCPU(), "the number of pages to render concurrently")
+	flags.StringVar(&frontMatterFile, "front-matter", "", "a yaml file configuring the front matter keys added to the pages and section indexes")
+	flags.StringVar(&frontMatterFormat, "front-matter-format", "", "the format of the front matter: yaml or toml (defaults to yaml)")
+	flags.StringVar(&errorFormat, "error-format", "text", "the format of the reported problems: text or json")
 	rootCmd.AddCommand(convert)
 }
 
@@ -28,14 +53,42 @@ var convert = &cobra.Command{
 	Short: "convert [path]",
 	Args:  validatePaths(),
 	Run: func(cmd *cobra.Command, args []string) {
+		if markdown.IndexOf(errorFormats, errorFormat) < 0 {
+			log.Fatalf(`invalid error format "%s", expected one of %s`, errorFormat, strings.Join(errorFormats, ", "))
+		}
+		frontMatter, err := loadFrontMatter(frontMatterFile, frontMatterFormat)
+		if err != nil {
+			log.Fatal(err)
+		}
+		config.FrontMatter = frontMatter
+
 		c := markdown.NewConverter(config)
 		if err := c.Convert(args[0]); err != nil {
-			log.Fatal(err)
+			reportError(err, errorFormat)
 		}
 		return
 	},
 }
 
+// reportError prints the problems of a failed conversion in the chosen format and exits
+func reportError(err error, format string) {
+	var convertErr *markdown.ConvertError
+	if !errors.As(err, &convertErr) {
+		log.Fatal(err)
+	}
+
+	switch format {
+	case "json":
+		err = convertErr.WriteJSON(os.Stdout)
+	default:
+		err = convertErr.WriteText(os.Stderr)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(1)
+}
+
 func validatePaths() cobra.PositionalArgs {
 	return func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"io"
+	"io/fs"
+	"log"
+	"path/filepath"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/coverage"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/cobra"
+)
+
+var coverageConfig markdown.Config
+var coverageFrontMatterFile string
+var coverageFrontMatterFormat string
+
+func init() {
+	flags := coverageCmd.Flags()
+	flags.StringVarP(&coverageConfig.Destination, "destination", "d", ".", "the output directory of coverage.md and coverage.json")
+	flags.StringVarP(&coverageConfig.Extension, "extension", "e", "*.schema.json", "the schema extension")
+	flags.BoolVarP(&coverageConfig.Recursive, "walk", "w", false, "walk through sub-directories")
+	flags.StringVar(&coverageFrontMatterFile, "front-matter", "", "a yaml file configuring the front matter keys added to coverage.md")
+	flags.StringVar(&coverageFrontMatterFormat, "front-matter-format", "", "the format of the front matter: yaml or toml (defaults to yaml)")
+	rootCmd.AddCommand(coverageCmd)
+}
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [path]",
+	Short: "coverage [path]",
+	Long:  "Reports the percentage of properties and definitions documented with descriptions, examples and titles.",
+	Args:  validatePaths(),
+	Run: func(cmd *cobra.Command, args []string) {
+		frontMatter, err := loadFrontMatter(coverageFrontMatterFile, coverageFrontMatterFormat)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		c := markdown.NewConverter(coverageConfig)
+		schemas, err := c.Load(args[0])
+		if err != nil {
+			reportError(err, "text")
+		}
+
+		report := coverage.Measure(schemas)
+		if err := markdown.AppFS.MkdirAll(coverageConfig.Destination, fs.ModePerm); err != nil {
+			log.Fatal(err)
+		}
+		writeMarkdown := func(w io.Writer) error { return report.WriteMarkdown(w, frontMatter) }
+		if err := writeFile(filepath.Join(coverageConfig.Destination, "coverage.md"), writeMarkdown); err != nil {
+			log.Fatal(err)
+		}
+		if err := writeFile(filepath.Join(coverageConfig.Destination, "coverage.json"), report.WriteJSON); err != nil {
+			log.Fatal(err)
+		}
+
+		total := report.Total
+		log.Printf("documented %s of %d properties and %s of %d definitions",
+			markdown.Percent(total.Properties.Descriptions, total.Properties.Total), total.Properties.Total,
+			markdown.Percent(total.Definitions.Descriptions, total.Definitions.Total), total.Definitions.Total)
+	},
+}
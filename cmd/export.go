@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/export"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/cobra"
+)
+
+var exportConfig markdown.Config
+var exportTo string
+
+func init() {
+	flags := exportCmd.Flags()
+	flags.StringVarP(&exportConfig.Destination, "destination", "d", ".", "the output directory of the exported files")
+	flags.StringVarP(&exportConfig.Extension, "extension", "e", "*.schema.json", "the schema extension")
+	flags.BoolVarP(&exportConfig.Recursive, "walk", "w", false, "walk through sub-directories")
+	flags.StringVar(&exportTo, "to", "avro", "the format the schemas are exported to: avro or proto")
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [path]",
+	Short: "export [path]",
+	Long:  "Exports the schemas to Avro or protocol buffers, one file per schema file, warning about the keywords the format cannot represent.",
+	Args:  validatePaths(),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, err := export.FindFormat(exportTo)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// the fields of protocol buffers messages are numbered after the declared order of
+		// the properties
+		exportConfig.Ordered = true
+		c := markdown.NewConverter(exportConfig)
+		if _, ok := format.(export.Proto); ok {
+			format = export.Proto{Converter: c}
+		}
+		schemas, err := c.Load(args[0])
+		if err != nil {
+			reportError(err, "text")
+		}
+		writeGenerated(format, schemas, exportConfig.Destination, "exported")
+	},
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/lint"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/cobra"
+)
+
+var lintConfig markdown.Config
+var lintRules map[string]string
+var lintFailOn string
+var lintFormat string
+
+func init() {
+	flags := lintCmd.Flags()
+	flags.StringVarP(&lintConfig.Extension, "extension", "e", "*.schema.json", "the schema extension")
+	flags.BoolVarP(&lintConfig.Recursive, "walk", "w", false, "walk through sub-directories")
+	flags.StringToStringVarP(&lintRules, "rule", "r", nil, "override the severity of a rule, e.g. missing-title=off (off, info, warning or error)")
+	flags.StringVar(&lintFailOn, "fail-on", "error", "exit with a non-zero status when a finding has at least this severity")
+	flags.StringVar(&lintFormat, "format", "text", "the format of the findings: text or json")
+	rootCmd.AddCommand(lintCmd)
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [path]",
+	Short: "lint [path]",
+	Long:  "Checks the schemas for documentation-quality problems.\n\nRules:\n" + lintRuleList(),
+	Args:  validatePaths(),
+	Run: func(cmd *cobra.Command, args []string) {
+		failOn, err := lint.ParseSeverity(lintFailOn)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		linter, err := lint.NewLinter(lintRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		c := markdown.NewConverter(lintConfig)
+		schemas, err := c.Load(args[0])
+		if err != nil {
+			reportError(err, lintFormat)
+		}
+
+		findings := linter.Lint(schemas, c.Document)
+		if lintFormat == "json" {
+			err = lint.WriteJSON(os.Stdout, findings)
+		} else {
+			err = lint.WriteText(os.Stdout, findings)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if failOn != lint.Off && lint.Exceeds(findings, failOn) {
+			os.Exit(1)
+		}
+	},
+}
+
+func lintRuleList() string {
+	var list string
+	for _, rule := range lint.Rules() {
+		list += "  " + rule.Name + " (" + rule.Severity.String() + "): " + rule.Description + "\n"
+	}
+	return list
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var sampleConfig markdown.Config
+
+// sampleKinds are the samples synthesised for each schema, in the order they are reported
+var sampleKinds = []struct {
+	name string
+	full bool
+}{
+	{"minimal", false},
+	{"full", true},
+}
+
+func init() {
+	flags := sampleCmd.Flags()
+	flags.StringVarP(&sampleConfig.Destination, "destination", "d", "", "the output directory, the samples are printed when omitted")
+	flags.StringVarP(&sampleConfig.Extension, "extension", "e", "*.schema.json", "the schema extension")
+	flags.BoolVarP(&sampleConfig.Recursive, "walk", "w", false, "walk through sub-directories")
+	rootCmd.AddCommand(sampleCmd)
+}
+
+var sampleCmd = &cobra.Command{
+	Use:   "sample [path]",
+	Short: "sample [path]",
+	Long:  "Synthesises a minimal and a full example instance of each schema, validated against the schema.",
+	Args:  validatePaths(),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := markdown.NewConverter(sampleConfig)
+		schemas, err := c.Load(args[0])
+		if err != nil {
+			reportError(err, "text")
+		}
+
+		samples := map[string]map[string]interface{}{}
+		for _, schema := range schemas {
+			samples[schema.Path] = map[string]interface{}{}
+			for _, kind := range sampleKinds {
+				instance, err := markdown.Sample(schema.Schema, kind.full)
+				if err != nil {
+					log.Printf("failed to synthesise a valid %s sample for %s: %v", kind.name, schema.Path, err)
+					continue
+				}
+				samples[schema.Path][kind.name] = instance
+			}
+		}
+
+		if len(sampleConfig.Destination) == 0 {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(samples); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		if err := markdown.AppFS.MkdirAll(sampleConfig.Destination, fs.ModePerm); err != nil {
+			log.Fatal(err)
+		}
+		for path, kinds := range samples {
+			for kind, instance := range kinds {
+				b, err := json.MarshalIndent(instance, "", "  ")
+				if err != nil {
+					log.Fatal(err)
+				}
+				name := fmt.Sprintf("%s.%s.json", markdown.Slugify(markdown.FilenameWithoutExt(path)), kind)
+				if err := afero.WriteFile(markdown.AppFS, filepath.Join(sampleConfig.Destination, name), b, os.ModePerm); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	},
+}
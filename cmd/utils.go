@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/codegen"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/afero"
+)
+
+// writeFile creates the file at path and writes its content with write
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := markdown.AppFS.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return write(f)
+}
+
+// loadFrontMatter returns the front matter configured by the yaml file at path, when set,
+// written in format, when set
+func loadFrontMatter(path, format string) (markdown.FrontMatter, error) {
+	var fm markdown.FrontMatter
+	if len(path) > 0 {
+		var err error
+		if fm, err = markdown.LoadFrontMatter(path); err != nil {
+			return fm, err
+		}
+	}
+	if len(format) > 0 {
+		fm.Format = format
+	}
+	return fm, fm.Validate()
+}
+
+// writeGenerated generates the files of the schemas in lang and writes them to destination,
+// logging the warnings of the generator and each file written, e.g. "exported order.avsc"
+func writeGenerated(lang codegen.Language, schemas []*markdown.Schema, destination, verb string) {
+	g := codegen.NewGenerator(lang)
+	files, err := g.Generate(schemas)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, w := range g.Warnings() {
+		log.Printf("warning: %s", w)
+	}
+
+	if err := markdown.AppFS.MkdirAll(destination, fs.ModePerm); err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range files {
+		path := filepath.Join(destination, f.Path)
+		if err := afero.WriteFile(markdown.AppFS, path, f.Content, os.ModePerm); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%s %s", verb, path)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/SPANDigital/presidium-json-schema
 go 1.18
 
 require (
+	github.com/dlclark/regexp2 v1.10.0
 	github.com/iancoleman/orderedmap v0.2.0
 	github.com/iancoleman/strcase v0.2.0
 	github.com/pkg/errors v0.9.1
@@ -13,6 +14,7 @@ require (
 	github.com/stretchr/testify v1.8.0
 	golang.org/x/text v0.3.7
 	gopkg.in/errgo.v2 v2.1.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -22,5 +24,4 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
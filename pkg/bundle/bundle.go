@@ -0,0 +1,364 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/iancoleman/orderedmap"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// dataKeywords hold instance data rather than schemas, so any $ref within them is kept as is
+var dataKeywords = map[string]bool{
+	"const":    true,
+	"default":  true,
+	"enum":     true,
+	"examples": true,
+}
+
+// Bundler inlines the external references of a root schema into the $defs of a single,
+// self-contained document.
+type Bundler struct {
+	converter *markdown.Converter
+	ordered   bool
+
+	// files maps the url of each loaded schema document, by file and by $id, to its path
+	files map[string]string
+	root  string
+	names map[string]string
+	defs  *orderedmap.OrderedMap
+}
+
+// NewBundler returns a bundler loading schemas with the converter. The key order of the
+// schemas is preserved when ordered is set, otherwise keys are sorted alphabetically.
+func NewBundler(converter *markdown.Converter, ordered bool) *Bundler {
+	return &Bundler{
+		converter: converter,
+		ordered:   ordered,
+		files:     map[string]string{},
+		names:     map[string]string{},
+		defs:      newMap(),
+	}
+}
+
+// Bundle loads the schemas next to the root schema, the same way the converter does, and
+// returns the root schema with every external schema it references inlined in $defs.
+// References to remote schemas which were not loaded are kept as absolute urls. The problems
+// of the documents the root does not reference are logged rather than returned.
+func (b *Bundler) Bundle(root string) (*orderedmap.OrderedMap, error) {
+	schemas, err := b.converter.Load(filepath.Dir(root))
+	var loadErr *markdown.ConvertError
+	if err != nil && !errors.As(err, &loadErr) {
+		return nil, err
+	}
+	for _, s := range schemas {
+		b.index(s)
+	}
+
+	rootURL := FileURL(root)
+	if _, ok := b.files[rootURL]; !ok {
+		s, err := b.converter.LoadFile(root)
+		if err != nil {
+			return nil, err
+		}
+		b.index(s)
+	}
+	b.root = b.document(rootURL)
+
+	doc, err := b.content(root)
+	if err != nil {
+		return nil, err
+	}
+
+	base, _ := url.Parse(b.root)
+	bundled, err := b.rewrite(doc, base)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.reached(loadErr); err != nil {
+		return nil, err
+	}
+
+	result := bundled.(*orderedmap.OrderedMap)
+	if len(b.defs.Keys()) == 0 {
+		return result, nil
+	}
+
+	defs := newMap()
+	if existing, ok := result.Get("$defs"); ok {
+		if existing, ok := existing.(*orderedmap.OrderedMap); ok {
+			defs = existing
+		}
+	}
+	for _, name := range b.defs.Keys() {
+		def, _ := b.defs.Get(name)
+		defs.Set(name, def)
+	}
+	result.Set("$defs", defs)
+	return result, nil
+}
+
+// Validate compiles the bundled document with the converter to check that every
+// reference resolves
+func (b *Bundler) Validate(bundled *orderedmap.OrderedMap) error {
+	content, err := json.Marshal(bundled)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode bundle")
+	}
+
+	location := strings.TrimSuffix(b.root, filepath.Ext(b.root)) + ".bundle.json"
+	if err = b.converter.AddResource(location, content); err != nil {
+		return err
+	}
+	_, err = b.converter.Compile(location)
+	return err
+}
+
+// reached returns the problems of loading the documents the bundle includes, the problems
+// of the other documents next to the root are logged
+func (b *Bundler) reached(err *markdown.ConvertError) error {
+	if err == nil {
+		return nil
+	}
+
+	var problems []markdown.Problem
+	for _, p := range err.Problems {
+		document := FileURL(p.File)
+		if _, ok := b.names[document]; ok || document == b.root {
+			problems = append(problems, p)
+			continue
+		}
+		log.Warnf("ignoring a schema the root does not reference: %v", p)
+	}
+	if len(problems) > 0 {
+		return &markdown.ConvertError{Problems: problems}
+	}
+	return nil
+}
+
+// index records the urls under which a loaded schema can be referenced
+func (b *Bundler) index(s *markdown.Schema) {
+	b.files[FileURL(s.Path)] = s.Path
+	b.files[markdown.TrimAnchorPath(s.Location)] = s.Path
+	if id := b.converter.Document(s.Path).Id(); len(id) > 0 {
+		b.files[strings.TrimSuffix(id, "#")] = s.Path
+	}
+}
+
+// document returns the canonical url of a document, which is its file url when loaded
+func (b *Bundler) document(location string) string {
+	if path, ok := b.files[location]; ok {
+		return FileURL(path)
+	}
+	return location
+}
+
+// content returns the schema file at path as an ordered map
+func (b *Bundler) content(path string) (*orderedmap.OrderedMap, error) {
+	if b.ordered {
+		if doc := b.converter.Order(path); doc != nil {
+			return doc, nil
+		}
+	}
+
+	raw, err := json.Marshal(b.converter.Document(path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode schema: %s", path)
+	}
+	doc := newMap()
+	if err = json.Unmarshal(raw, doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode schema: %s", path)
+	}
+	return doc, nil
+}
+
+// rewrite copies a json value, rewriting the $refs it contains against base
+func (b *Bundler) rewrite(v interface{}, base *url.URL) (interface{}, error) {
+	switch value := v.(type) {
+	case orderedmap.OrderedMap:
+		return b.rewrite(&value, base)
+	case *orderedmap.OrderedMap:
+		if id, ok := value.Get("$id"); ok {
+			if id, ok := id.(string); ok {
+				if u, err := url.Parse(id); err == nil {
+					base = base.ResolveReference(u)
+				}
+			}
+		}
+
+		result := newMap()
+		for _, key := range value.Keys() {
+			item, _ := value.Get(key)
+			if ref, ok := item.(string); ok && key == "$ref" {
+				rewritten, err := b.ref(ref, base)
+				if err != nil {
+					return nil, err
+				}
+				result.Set(key, rewritten)
+				continue
+			}
+
+			if dataKeywords[key] {
+				result.Set(key, item)
+				continue
+			}
+
+			rewritten, err := b.rewrite(item, base)
+			if err != nil {
+				return nil, err
+			}
+			result.Set(key, rewritten)
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			rewritten, err := b.rewrite(item, base)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = rewritten
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+// ref rewrites a $ref to point within the bundled document
+func (b *Bundler) ref(ref string, base *url.URL) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid $ref: %s", ref)
+	}
+
+	target := base.ResolveReference(u)
+	fragment := target.Fragment
+	target.Fragment = ""
+	document := b.document(target.String())
+
+	if document == b.root {
+		return "#" + fragment, nil
+	}
+
+	path, ok := b.files[target.String()]
+	if !ok {
+		path, ok = b.files[document]
+	}
+	if !ok {
+		if target.Scheme != "file" {
+			log.Debugf("keeping remote reference: %s", target)
+			return b.absolute(target, fragment), nil
+		}
+		s, err := b.converter.LoadFile(markdown.FileFromURL(document))
+		if err != nil {
+			return "", err
+		}
+		b.index(s)
+		path = s.Path
+	}
+
+	if len(fragment) > 0 && !strings.HasPrefix(fragment, "/") {
+		log.Warnf("keeping reference to anchor %s, anchors of inlined schemas are not rewritten", ref)
+		return b.absolute(target, fragment), nil
+	}
+
+	name, err := b.include(path)
+	if err != nil {
+		return "", err
+	}
+	return "#/$defs/" + escape(name) + fragment, nil
+}
+
+func (b *Bundler) absolute(target *url.URL, fragment string) string {
+	if len(fragment) > 0 {
+		return target.String() + "#" + fragment
+	}
+	return target.String()
+}
+
+// include inlines the schema file at path into $defs, once, and returns its name
+func (b *Bundler) include(path string) (string, error) {
+	document := FileURL(path)
+	if name, ok := b.names[document]; ok {
+		return name, nil
+	}
+
+	name := b.uniqueName(markdown.Slugify(markdown.FilenameWithoutExt(path)))
+	b.names[document] = name
+	// reserve the position of the definition before inlining its own references
+	b.defs.Set(name, nil)
+
+	doc, err := b.content(path)
+	if err != nil {
+		return "", err
+	}
+
+	base, _ := url.Parse(document)
+	if id, ok := doc.Get("$id"); ok {
+		if id, ok := id.(string); ok {
+			if u, err := url.Parse(id); err == nil {
+				base = base.ResolveReference(u)
+			}
+		}
+	}
+
+	inlined, err := b.rewrite(doc, base)
+	if err != nil {
+		return "", err
+	}
+
+	def := inlined.(*orderedmap.OrderedMap)
+	def.Delete("$id")
+	def.Delete("$schema")
+	b.defs.Set(name, def)
+	return name, nil
+}
+
+func (b *Bundler) uniqueName(name string) string {
+	used := map[string]bool{}
+	for _, key := range b.defs.Keys() {
+		used[key] = true
+	}
+	if doc, err := b.content(markdown.FileFromURL(b.root)); err == nil {
+		if defs, ok := doc.Get("$defs"); ok {
+			if defs, ok := defs.(orderedmap.OrderedMap); ok {
+				for _, key := range defs.Keys() {
+					used[key] = true
+				}
+			}
+		}
+	}
+
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	return unique
+}
+
+// FileURL returns the absolute file url of path, the way the compiler locates files
+func FileURL(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func newMap() *orderedmap.OrderedMap {
+	m := orderedmap.New()
+	m.SetEscapeHTML(false)
+	return m
+}
+
+// escape escapes a json pointer token
+func escape(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
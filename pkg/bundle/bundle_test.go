@@ -0,0 +1,112 @@
+package bundle
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSchemas(t *testing.T) {
+	markdown.AppFS = afero.NewMemMapFs()
+	files := map[string]string{
+		"/bundle/order.schema.json": `{
+			"title": "Order",
+			"type": "object",
+			"properties": {
+				"customer": {"$ref": "customer.schema.json"},
+				"address": {"$ref": "customer.schema.json#/definitions/address"},
+				"self": {"$ref": "#/properties/customer"},
+				"meta": {"$ref": "https://json-schema.org/draft/2020-12/schema"}
+			}
+		}`,
+		"/bundle/customer.schema.json": `{
+			"$id": "https://example.com/customer.json",
+			"title": "Customer",
+			"type": "object",
+			"properties": {
+				"address": {"$ref": "#/definitions/address"},
+				"orders": {"type": "array", "items": {"$ref": "order.json"}}
+			},
+			"definitions": {"address": {"type": "string"}}
+		}`,
+		"/bundle/order-id.schema.json": `{"$id": "https://example.com/order.json", "type": "string"}`,
+	}
+	for path, content := range files {
+		assert.Nil(t, afero.WriteFile(markdown.AppFS, path, []byte(content), os.ModePerm))
+	}
+}
+
+func bundle(t *testing.T, ordered bool) map[string]interface{} {
+	writeSchemas(t)
+	config := markdown.Config{Extension: "*.schema.json", Ordered: ordered}
+	b := NewBundler(markdown.NewConverter(config), ordered)
+	bundled, err := b.Bundle("/bundle/order.schema.json")
+	assert.Nil(t, err)
+	assert.Nil(t, b.Validate(bundled))
+
+	content, err := json.Marshal(bundled)
+	assert.Nil(t, err)
+	var result map[string]interface{}
+	assert.Nil(t, json.Unmarshal(content, &result))
+	return result
+}
+
+func ref(t *testing.T, v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		assert.True(t, ok, key)
+		v = m[key]
+	}
+	return v
+}
+
+func TestBundler_Bundle(t *testing.T) {
+	result := bundle(t, false)
+
+	assert.Equal(t, "#/$defs/customer-schema", ref(t, result, "properties", "customer", "$ref"))
+	assert.Equal(t, "#/$defs/customer-schema/definitions/address", ref(t, result, "properties", "address", "$ref"))
+	assert.Equal(t, "#/properties/customer", ref(t, result, "properties", "self", "$ref"))
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", ref(t, result, "properties", "meta", "$ref"))
+
+	customer := ref(t, result, "$defs", "customer-schema")
+	assert.Nil(t, ref(t, customer, "$id"))
+	assert.Equal(t, "#/$defs/customer-schema/definitions/address", ref(t, customer, "properties", "address", "$ref"))
+	assert.Equal(t, "#/$defs/order-id-schema", ref(t, customer, "properties", "orders", "items", "$ref"))
+	assert.Equal(t, "string", ref(t, result, "$defs", "order-id-schema", "type"))
+}
+
+func TestBundler_BundleOrdered(t *testing.T) {
+	writeSchemas(t)
+	config := markdown.Config{Extension: "*.schema.json", Ordered: true}
+	bundled, err := NewBundler(markdown.NewConverter(config), true).Bundle("/bundle/order.schema.json")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"title", "type", "properties", "$defs"}, bundled.Keys())
+
+	bundled, err = NewBundler(markdown.NewConverter(markdown.Config{Extension: "*.schema.json"}), false).Bundle("/bundle/order.schema.json")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"properties", "title", "type", "$defs"}, bundled.Keys())
+}
+
+func TestBundler_BundleBrokenSibling(t *testing.T) {
+	writeSchemas(t)
+	assert.Nil(t, afero.WriteFile(markdown.AppFS, "/bundle/broken.schema.json", []byte(`{"type": 1}`), os.ModePerm))
+	config := markdown.Config{Extension: "*.schema.json"}
+
+	// a broken schema the root does not reference is ignored
+	b := NewBundler(markdown.NewConverter(config), false)
+	bundled, err := b.Bundle("/bundle/order.schema.json")
+	assert.Nil(t, err)
+	assert.Nil(t, b.Validate(bundled))
+
+	// a broken schema the root references is not
+	assert.Nil(t, afero.WriteFile(markdown.AppFS, "/bundle/root.schema.json", []byte(`{
+		"type": "object",
+		"properties": {"broken": {"$ref": "broken.schema.json"}}
+	}`), os.ModePerm))
+	_, err = NewBundler(markdown.NewConverter(config), false).Bundle("/bundle/root.schema.json")
+	assert.NotNil(t, err)
+}
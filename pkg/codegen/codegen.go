@@ -0,0 +1,357 @@
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Language renders the types declared by a module as source code
+type Language interface {
+	// Extension returns the extension of the generated files, e.g. .d.ts
+	Extension() string
+	// Render returns the source code declaring the types of the module
+	Render(m *Module) ([]byte, error)
+}
+
+// SharedScope is implemented by the languages whose generated files declare their types in a
+// single scope, such as the files of a go package, whose type names must be unique across files
+type SharedScope interface {
+	SharedScope() bool
+}
+
+// Languages are the languages types are generated for, by name
+var Languages = map[string]Language{
+	"go": Golang{},
+	"ts": TypeScript{},
+}
+
+// FindLanguage returns the language named name
+func FindLanguage(name string) (Language, error) {
+	if lang, ok := Languages[name]; ok {
+		return lang, nil
+	}
+	var names []string
+	for n := range Languages {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf(`invalid language "%s", expected one of %s`, name, strings.Join(names, ", "))
+}
+
+// Declaration is a named type generated for a schema
+type Declaration struct {
+	Name   string
+	Schema *jsonschema.Schema
+	Module *Module
+}
+
+// Import lists the types a module uses from another module
+type Import struct {
+	Module *Module
+	Names  []string
+}
+
+// Warning is a keyword of a schema the generated file cannot represent
+type Warning struct {
+	Location string
+	Message  string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s#%s: %s", markdown.FileFromURL(markdown.TrimAnchorPath(w.Location)), markdown.Pointer(w.Location), w.Message)
+}
+
+// Module is the generated file of a schema document, declaring its root schema and the
+// schemas of the document other schemas reference
+type Module struct {
+	// Name is the name of the file without its extension, e.g. order for order.schema.json
+	Name         string
+	Document     string
+	Declarations []*Declaration
+
+	names     map[string]bool
+	generator *Generator
+	imports   map[*Module]map[string]bool
+	warnings  []Warning
+}
+
+// Ref returns the name of the type declared for s, recording its import when it is declared
+// by another module. It returns false when no type is declared for s.
+func (m *Module) Ref(s *jsonschema.Schema) (string, bool) {
+	d, ok := m.generator.declared[s.Location]
+	if !ok {
+		return "", false
+	}
+	if d.Module != m {
+		if m.imports[d.Module] == nil {
+			m.imports[d.Module] = map[string]bool{}
+		}
+		m.imports[d.Module][d.Name] = true
+	}
+	return d.Name, true
+}
+
+// Declaration returns the declaration of s, recording its import like Ref
+func (m *Module) Declaration(s *jsonschema.Schema) (*Declaration, bool) {
+	if _, ok := m.Ref(s); !ok {
+		return nil, false
+	}
+	return m.generator.declared[s.Location], true
+}
+
+// Warn records a warning about s, once per schema and message
+func (m *Module) Warn(s *jsonschema.Schema, format string, args ...interface{}) {
+	w := Warning{Location: s.Location, Message: fmt.Sprintf(format, args...)}
+	for _, warning := range m.warnings {
+		if warning == w {
+			return
+		}
+	}
+	m.warnings = append(m.warnings, w)
+}
+
+// Reserve returns name, numbered when the module already declares a type of that name, and
+// reserves it
+func (m *Module) Reserve(name string) string {
+	names := m.names
+	if m.generator.shared {
+		names = m.generator.names
+	}
+
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	names[unique] = true
+	return unique
+}
+
+// Imports returns the types the module used from other modules, sorted by module and name
+func (m *Module) Imports() []Import {
+	var imports []Import
+	for module, names := range m.imports {
+		i := Import{Module: module}
+		for name := range names {
+			i.Names = append(i.Names, name)
+		}
+		sort.Strings(i.Names)
+		imports = append(imports, i)
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		return imports[i].Module.Name < imports[j].Module.Name
+	})
+	return imports
+}
+
+// File is a generated source file
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Generator generates the types of compiled schemas in a language
+type Generator struct {
+	lang      Language
+	modules   []*Module
+	documents map[string]*Module
+	declared  map[string]*Declaration
+	visited   map[string]bool
+
+	// names are the names reserved across modules, when the language has a shared scope
+	names  map[string]bool
+	shared bool
+}
+
+func NewGenerator(lang Language) *Generator {
+	scope, ok := lang.(SharedScope)
+	return &Generator{
+		lang:      lang,
+		documents: map[string]*Module{},
+		declared:  map[string]*Declaration{},
+		visited:   map[string]bool{},
+		names:     map[string]bool{},
+		shared:    ok && scope.SharedScope(),
+	}
+}
+
+// Generate returns a file for each schema document, declaring a type for its root schema
+// and for each of its schemas another schema references
+func (g *Generator) Generate(schemas []*markdown.Schema) ([]File, error) {
+	for _, s := range schemas {
+		g.declare(s.Schema)
+	}
+	for _, s := range schemas {
+		g.discover(s)
+	}
+
+	var files []File
+	for _, m := range g.modules {
+		content, err := g.lang.Render(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", m.Document, err)
+		}
+		files = append(files, File{Path: m.Name + g.lang.Extension(), Content: content})
+	}
+	return files, nil
+}
+
+// Warnings returns the warnings of the generated files, in the order they were recorded
+func (g *Generator) Warnings() []Warning {
+	var warnings []Warning
+	for _, m := range g.modules {
+		warnings = append(warnings, m.warnings...)
+	}
+	return warnings
+}
+
+// declare declares a type for s in the module of its document
+func (g *Generator) declare(s *jsonschema.Schema) {
+	if _, ok := g.declared[s.Location]; ok {
+		return
+	}
+
+	m := g.module(markdown.TrimAnchorPath(s.Location))
+	d := &Declaration{Name: m.Reserve(TypeName(s)), Schema: s, Module: m}
+	m.Declarations = append(m.Declarations, d)
+	g.declared[s.Location] = d
+}
+
+// module returns the module of a document, creating it on first use
+func (g *Generator) module(document string) *Module {
+	if m, ok := g.documents[document]; ok {
+		return m
+	}
+
+	name := FileName(document)
+	for i := 2; g.moduleNamed(name); i++ {
+		name = fmt.Sprintf("%s-%d", FileName(document), i)
+	}
+	m := &Module{
+		Name:      name,
+		Document:  document,
+		names:     map[string]bool{},
+		generator: g,
+		imports:   map[*Module]map[string]bool{},
+	}
+	g.documents[document] = m
+	g.modules = append(g.modules, m)
+	return m
+}
+
+func (g *Generator) moduleNamed(name string) bool {
+	for _, m := range g.modules {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// discover declares the schemas referenced by s and its subschemas
+func (g *Generator) discover(s *markdown.Schema) {
+	if s == nil || g.visited[s.Location] {
+		return
+	}
+	g.visited[s.Location] = true
+
+	for _, def := range s.Definitions() {
+		g.declare(def.Schema)
+	}
+	// WalkSchema leaves out the additional properties and items, whose references are
+	// discovered on their own
+	s.WalkSchema(true, func(next *markdown.Schema) error {
+		for _, ref := range []*jsonschema.Schema{next.DynamicRef, next.RecursiveRef} {
+			if ref != nil {
+				g.declare(ref)
+			}
+		}
+		for _, additional := range []interface{}{markdown.AdditionalProperties(next.Schema), next.AdditionalItems} {
+			g.discover(markdown.ToSchema(markdown.AsSchema(additional), s.Path))
+		}
+		return nil
+	})
+}
+
+// PropertyNames returns the names of the properties of s in alphabetical order
+func PropertyNames(s *jsonschema.Schema) []string {
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRequired returns whether s requires the property name
+func IsRequired(s *jsonschema.Schema, name string) bool {
+	return markdown.IndexOf(s.Required, name) >= 0
+}
+
+// Target returns the schema s references, when it only holds a reference
+func Target(s *jsonschema.Schema) *jsonschema.Schema {
+	for s.Ref != nil && len(s.Properties) == 0 && len(s.AllOf) == 0 {
+		s = s.Ref
+	}
+	return s
+}
+
+// NonNull returns the types other than null
+func NonNull(types []string) []string {
+	var result []string
+	for _, t := range types {
+		if t != "null" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// TypeName returns the name of the type of s in PascalCase, after its title, the last token
+// of its json pointer, e.g. customer for #/$defs/customer, or the name of its file
+func TypeName(s *jsonschema.Schema) string {
+	name := s.Title
+	if len(name) == 0 {
+		pointer := markdown.Pointer(s.Location)
+		name = pointer[strings.LastIndex(pointer, "/")+1:]
+	}
+	if len(name) == 0 {
+		name = FileName(markdown.TrimAnchorPath(s.Location))
+	}
+
+	name = PascalCase(name)
+	if len(name) == 0 || unicode.IsDigit(rune(name[0])) {
+		name = "T" + name
+	}
+	return name
+}
+
+// FileName returns the name of a document without its extensions, e.g. order for
+// file:///schemas/order.schema.json
+func FileName(document string) string {
+	name := filepath.Base(markdown.FileFromURL(document))
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return markdown.Slugify(name)
+}
+
+// PascalCase joins the words of s, capitalizing the first letter of each, e.g.
+// shipping_address => ShippingAddress
+func PascalCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		runes := []rune(w)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	return b.String()
+}
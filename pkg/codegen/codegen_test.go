@@ -0,0 +1,104 @@
+package codegen
+
+import (
+	"os"
+	"testing"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const orderSchema = `{
+  "title": "Order",
+  "description": "An order\nplaced by a customer",
+  "type": "object",
+  "required": ["id", "status"],
+  "properties": {
+    "id": { "type": "string", "description": "The id" },
+    "status": { "enum": ["open", "closed"] },
+    "kind": { "const": "order" },
+    "total": { "type": ["number", "null"] },
+    "customer": { "$ref": "customer.schema.json" },
+    "lines": { "type": "array", "items": { "$ref": "#/$defs/line" } },
+    "pair": { "type": "array", "prefixItems": [{ "type": "string" }, { "type": "integer" }] },
+    "tags": { "type": "object", "additionalProperties": { "type": "string" } },
+    "x-meta": { "type": "object", "properties": { "a": { "type": "boolean" } } },
+    "payment": { "oneOf": [{ "$ref": "#/$defs/card" }, { "type": "string" }] },
+    "codes": { "type": "array", "items": { "anyOf": [{ "type": "string" }, { "type": "number" }] } }
+  },
+  "$defs": {
+    "line": { "type": "object", "properties": { "sku": { "type": "string", "deprecated": true } } },
+    "card": {
+      "allOf": [
+        { "$ref": "customer.schema.json#/$defs/address" },
+        { "type": "object", "properties": { "number": { "type": "string" } } }
+      ]
+    }
+  }
+}`
+
+const customerSchema = `{
+  "title": "Customer",
+  "type": "object",
+  "properties": { "name": { "type": "string" }, "address": { "$ref": "#/$defs/address" } },
+  "$defs": { "address": { "type": "object", "properties": { "city": { "type": "string" } } } }
+}`
+
+// generate generates the files of the order and customer schemas in lang
+func generate(t *testing.T, lang Language) map[string]string {
+	return generateFiles(t, lang, map[string]string{
+		"order.schema.json":    orderSchema,
+		"customer.schema.json": customerSchema,
+	})
+}
+
+// generateFiles generates the files of the schemas, by file name, in lang
+func generateFiles(t *testing.T, lang Language, documents map[string]string) map[string]string {
+	markdown.AppFS = afero.NewMemMapFs()
+	for name, schema := range documents {
+		assert.Nil(t, afero.WriteFile(markdown.AppFS, "/codegen/"+name, []byte(schema), os.ModePerm))
+	}
+
+	c := markdown.NewConverter(markdown.Config{Extension: "*.schema.json"})
+	schemas, err := c.Load("/codegen")
+	assert.Nil(t, err)
+
+	files, err := NewGenerator(lang).Generate(schemas)
+	assert.Nil(t, err)
+	result := map[string]string{}
+	for _, f := range files {
+		result[f.Path] = string(f.Content)
+	}
+	return result
+}
+
+func TestFindLanguage(t *testing.T) {
+	lang, err := FindLanguage("ts")
+	assert.Nil(t, err)
+	assert.Equal(t, TypeScript{}, lang)
+
+	_, err = FindLanguage("cobol")
+	assert.EqualError(t, err, `invalid language "cobol", expected one of go, ts`)
+}
+
+func TestTypeName(t *testing.T) {
+	for location, expected := range map[string]string{
+		"file:///a/order.schema.json#":                        "Order",
+		"file:///a/order.schema.json#/$defs/shipping_address": "ShippingAddress",
+		"file:///a/order.schema.json#/properties/x-meta":      "XMeta",
+		"file:///a/order.schema.json#/components/schemas/2fa": "T2fa",
+		"file:///a/user-profile.schema.json#":                 "UserProfile",
+	} {
+		assert.Equal(t, expected, TypeName(&jsonschema.Schema{Location: location}), location)
+	}
+	assert.Equal(t, "LineItem", TypeName(&jsonschema.Schema{Location: "file:///a.json#/$defs/x", Title: "Line item"}))
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	files := generate(t, TypeScript{})
+	assert.Len(t, files, 2)
+	assert.Contains(t, files, "order.d.ts")
+	assert.Contains(t, files, "customer.d.ts")
+}
@@ -0,0 +1,320 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// initialisms are the words go names spell in upper case
+var initialisms = map[string]bool{
+	"Api": true, "Http": true, "Id": true, "Ip": true, "Json": true, "Sku": true, "Uri": true, "Url": true, "Uuid": true,
+}
+
+// Golang generates .go files: a struct for each object schema, with json tags and pointers to
+// the optional properties, a named type for any other schema and constants for its enum
+type Golang struct {
+	// Package is the package of the generated files, which defaults to schemas
+	Package string
+}
+
+func (Golang) Extension() string {
+	return ".go"
+}
+
+// SharedScope returns true, the generated files belonging to the same package
+func (Golang) SharedScope() bool {
+	return true
+}
+
+func (g Golang) Render(m *Module) ([]byte, error) {
+	r := &goRenderer{module: m}
+	for _, d := range m.Declarations {
+		r.declare(d.Name, d.Schema)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by presidium-json codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n", markdown.FirstNonEmpty(g.Package, "schemas"))
+	if strings.Contains(r.body.String(), "json.RawMessage") {
+		b.WriteString("\nimport \"encoding/json\"\n")
+	}
+	b.WriteString(r.body.String())
+
+	source, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the go source: %w", err)
+	}
+	return source, nil
+}
+
+// goRenderer renders the declarations of a module, along with the named types of the
+// objects and enums nested in them
+type goRenderer struct {
+	module  *Module
+	body    strings.Builder
+	pending []*Declaration
+}
+
+// declare writes the declaration of the type name for s, followed by the types nested in it
+func (r *goRenderer) declare(name string, s *jsonschema.Schema) {
+	pending := r.pending
+	r.pending = nil
+
+	var decl strings.Builder
+	decl.WriteString("\n")
+	decl.WriteString(goDoc(s))
+
+	switch {
+	case isStruct(s):
+		fmt.Fprintf(&decl, "type %s %s\n", name, r.structType(name, s))
+	case len(enum(s)) > 0:
+		fmt.Fprintf(&decl, "type %s %s\n", name, r.expression(name, s, true))
+		decl.WriteString(r.constants(name, s))
+	default:
+		fmt.Fprintf(&decl, "type %s %s\n", name, r.expression(name, s, true))
+	}
+	r.body.WriteString(decl.String())
+
+	nested := r.pending
+	r.pending = pending
+	for _, d := range nested {
+		r.declare(d.Name, d.Schema)
+	}
+}
+
+// isStruct returns whether s is declared as a struct: an object with properties, or the
+// composition of such objects
+func isStruct(s *jsonschema.Schema) bool {
+	if len(s.AnyOf)+len(s.OneOf) > 0 || s.Enum != nil || s.Constant != nil {
+		return false
+	}
+	if len(s.Types) > 1 || (len(s.Types) == 1 && s.Types[0] != "object") {
+		return false
+	}
+	if len(s.Properties) > 0 {
+		return true
+	}
+	if len(s.AllOf) == 0 {
+		return false
+	}
+	for _, sub := range s.AllOf {
+		if !isStruct(Target(sub)) {
+			return false
+		}
+	}
+	return true
+}
+
+// expression returns the go type of s, which is the declared type when declared is false and
+// s has a declaration. Objects and enums nested in s are declared as types named after name.
+func (r *goRenderer) expression(name string, s *jsonschema.Schema, declared bool) string {
+	if !declared {
+		if ref, ok := r.module.Ref(s); ok {
+			return ref
+		}
+	}
+	if s.Always != nil {
+		return "interface{}"
+	}
+	if len(s.AllOf) == 0 && len(s.Properties) == 0 && len(s.Types) == 0 {
+		for _, ref := range []*jsonschema.Schema{s.Ref, s.DynamicRef, s.RecursiveRef} {
+			if ref != nil {
+				return r.expression(name, ref, false)
+			}
+		}
+	}
+	if len(s.AnyOf)+len(s.OneOf) > 0 {
+		return "json.RawMessage"
+	}
+	if !declared && (isStruct(s) || len(enum(s)) > 0) {
+		name = r.module.Reserve(name)
+		r.pending = append(r.pending, &Declaration{Name: name, Schema: s, Module: r.module})
+		return name
+	}
+	if len(s.AllOf) > 0 && len(s.Types) == 0 {
+		return r.expression(name, s.AllOf[0], false)
+	}
+
+	switch types := NonNull(s.Types); {
+	case len(s.Constant) > 0:
+		return valueType(s.Constant[0])
+	case len(enum(s)) > 0:
+		return valueType(s.Enum[0])
+	case len(types) == 0 && (len(s.PatternProperties) > 0 || markdown.AsSchema(markdown.AdditionalProperties(s)) != nil):
+		return r.mapType(name, s)
+	case len(types) != 1:
+		return "interface{}"
+	default:
+		switch types[0] {
+		case "string":
+			return "string"
+		case "integer":
+			return "int64"
+		case "number":
+			return "float64"
+		case "boolean":
+			return "bool"
+		case "array":
+			return r.arrayType(name, s)
+		default:
+			return r.mapType(name, s)
+		}
+	}
+}
+
+// arrayType returns a slice of the items of s, or of interface{} for tuples
+func (r *goRenderer) arrayType(name string, s *jsonschema.Schema) string {
+	items := s.Items2020
+	if i, ok := s.Items.(*jsonschema.Schema); ok {
+		items = i
+	}
+	if items == nil || len(s.PrefixItems) > 0 {
+		return "[]interface{}"
+	}
+	return "[]" + r.expression(name+"Item", items, false)
+}
+
+// mapType returns a map of the additional properties of s
+func (r *goRenderer) mapType(name string, s *jsonschema.Schema) string {
+	if additional := markdown.AsSchema(markdown.AdditionalProperties(s)); additional != nil && len(s.PatternProperties) == 0 {
+		return "map[string]" + r.expression(name+"Value", additional, false)
+	}
+	return "map[string]interface{}"
+}
+
+// structType returns a struct with a field for each property of s, and an embedded field for
+// each branch of its allOf composition
+func (r *goRenderer) structType(name string, s *jsonschema.Schema) string {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, sub := range s.AllOf {
+		if ref, ok := r.module.Ref(Target(sub)); ok {
+			fmt.Fprintf(&b, "%s\n", ref)
+			continue
+		}
+		// the properties of an inline branch are fields of the struct itself
+		b.WriteString(r.fields(name, Target(sub)))
+	}
+	b.WriteString(r.fields(name, s))
+	b.WriteString("}")
+	return b.String()
+}
+
+// fields returns the fields of the properties of s: a pointer tagged omitempty unless the
+// property is required
+func (r *goRenderer) fields(name string, s *jsonschema.Schema) string {
+	var b strings.Builder
+	for _, property := range PropertyNames(s) {
+		p := s.Properties[property]
+		field := GoName(property)
+		b.WriteString(goDoc(p))
+
+		t := r.expression(name+field, p, false)
+		tag := property
+		if !IsRequired(s, property) {
+			tag += ",omitempty"
+		}
+		if (!IsRequired(s, property) || nullable(p)) && !strings.HasPrefix(t, "[]") && !strings.HasPrefix(t, "map[") &&
+			t != "interface{}" && t != "json.RawMessage" {
+			t = "*" + t
+		}
+		fmt.Fprintf(&b, "%s %s `json:%q`\n", field, t, tag)
+	}
+	return b.String()
+}
+
+// constants returns the constants of the values of the enum type name
+func (r *goRenderer) constants(name string, s *jsonschema.Schema) string {
+	var b strings.Builder
+	b.WriteString("\nconst (\n")
+	for i, v := range enum(s) {
+		constant := name + GoName(fmt.Sprint(v))
+		if len(GoName(fmt.Sprint(v))) == 0 {
+			constant = fmt.Sprintf("%s%d", name, i+1)
+		}
+		// the constants share the scope of the types of the package
+		constant = r.module.Reserve(constant)
+		value, _ := json.Marshal(v)
+		fmt.Fprintf(&b, "%s %s = %s\n", constant, name, value)
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// enum returns the values of the enum of s when they are all strings or all numbers
+func enum(s *jsonschema.Schema) []interface{} {
+	if len(s.Enum) == 0 {
+		return nil
+	}
+	t := valueType(s.Enum[0])
+	for _, v := range s.Enum {
+		if valueType(v) != t || t == "interface{}" || t == "bool" {
+			return nil
+		}
+	}
+	return s.Enum
+}
+
+// valueType returns the go type of a json value
+func valueType(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "int64"
+		}
+		return "float64"
+	case float64:
+		return "float64"
+	default:
+		return "interface{}"
+	}
+}
+
+// nullable returns whether s allows null besides another type
+func nullable(s *jsonschema.Schema) bool {
+	return markdown.IndexOf(s.Types, "null") >= 0 && len(s.Types) > 1
+}
+
+// goDoc returns the doc comment of the description of s, marking deprecated schemas
+func goDoc(s *jsonschema.Schema) string {
+	var lines []string
+	if len(s.Description) > 0 {
+		lines = strings.Split(s.Description, "\n")
+	}
+	if s.Deprecated {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "Deprecated: do not use.")
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+	}
+	return b.String()
+}
+
+// GoName returns the exported go name of a property, e.g. customer_id => CustomerID
+func GoName(s string) string {
+	name := PascalCase(s)
+	for initialism := range initialisms {
+		if strings.HasSuffix(name, initialism) {
+			name = strings.TrimSuffix(name, initialism) + strings.ToUpper(initialism)
+			break
+		}
+	}
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		name = "N" + name
+	}
+	return name
+}
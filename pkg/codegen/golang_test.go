@@ -0,0 +1,75 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGolang_Render(t *testing.T) {
+	files := generate(t, Golang{Package: "models"})
+
+	order := files["order.go"]
+	assert.Contains(t, order, "// Code generated by presidium-json codegen. DO NOT EDIT.\n\npackage models\n\nimport \"encoding/json\"\n")
+	assert.Contains(t, order, "// An order\n// placed by a customer\ntype Order struct {\n")
+	assert.Contains(t, order, "\t// The id\n\tID      string            `json:\"id\"`\n")
+	assert.Contains(t, order, "\tStatus  OrderStatus       `json:\"status\"`\n")
+	assert.Contains(t, order, "\tCustomer *Customer         `json:\"customer,omitempty\"`\n")
+	assert.Contains(t, order, "\tLines   []Line            `json:\"lines,omitempty\"`\n")
+	assert.Contains(t, order, "\tTags    map[string]string `json:\"tags,omitempty\"`\n")
+	assert.Contains(t, order, "\tTotal   *float64          `json:\"total,omitempty\"`\n")
+	assert.Contains(t, order, "\tXMeta   *OrderXMeta       `json:\"x-meta,omitempty\"`\n")
+	assert.Contains(t, order, "\tPayment json.RawMessage   `json:\"payment,omitempty\"`\n")
+	assert.Contains(t, order, "type OrderStatus string\n\nconst (\n\tOrderStatusOpen   OrderStatus = \"open\"\n\tOrderStatusClosed OrderStatus = \"closed\"\n)")
+	assert.Contains(t, order, "type OrderXMeta struct {\n\tA *bool `json:\"a,omitempty\"`\n}")
+	assert.Contains(t, order, "type Card struct {\n\tAddress\n\tNumber *string `json:\"number,omitempty\"`\n}")
+	assert.Contains(t, order, "type Line struct {\n\t// Deprecated: do not use.\n\tSKU *string `json:\"sku,omitempty\"`\n}")
+
+	customer := files["customer.go"]
+	assert.NotContains(t, customer, "import")
+	assert.Contains(t, customer, "type Customer struct {\n\tAddress *Address `json:\"address,omitempty\"`\n\tName    *string  `json:\"name,omitempty\"`\n}")
+}
+
+func TestGoName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"id":          "ID",
+		"customer_id": "CustomerID",
+		"x-meta":      "XMeta",
+		"imageUrl":    "ImageURL",
+		"2fa":         "N2fa",
+		"valid":       "Valid",
+	} {
+		assert.Equal(t, expected, GoName(name), name)
+	}
+}
+
+func TestGolang_RenderSharedScope(t *testing.T) {
+	files := generateFiles(t, Golang{Package: "models"}, map[string]string{
+		"customer.schema.json": `{
+  "title": "Customer",
+  "type": "object",
+  "properties": { "address": { "$ref": "#/$defs/address" } },
+  "$defs": { "address": { "title": "Address", "type": "object", "properties": { "city": { "type": "string" } } } }
+}`,
+		"order.schema.json": `{
+  "title": "Order",
+  "type": "object",
+  "properties": {
+    "address": { "$ref": "#/$defs/address" },
+    "status": { "enum": ["open"] },
+    "note": { "$ref": "#/$defs/orderStatusOpen" }
+  },
+  "$defs": {
+    "address": { "title": "Address", "type": "object", "properties": { "street": { "type": "string" } } },
+    "orderStatusOpen": { "title": "OrderStatusOpen", "type": "string" }
+  }
+}`,
+	})
+
+	// the files of a package share a scope, so names are unique across them
+	assert.Contains(t, files["customer.go"], "type Address struct {")
+	assert.Contains(t, files["order.go"], "type Address2 struct {")
+	assert.Regexp(t, "Address +\\*Address2 +`json:\"address,omitempty\"`", files["order.go"])
+	assert.Contains(t, files["order.go"], "type OrderStatusOpen string")
+	assert.Contains(t, files["order.go"], "OrderStatusOpen2 OrderStatus = \"open\"")
+}
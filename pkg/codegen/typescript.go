@@ -0,0 +1,268 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var identifierRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// TypeScript generates .d.ts files: an interface for each object schema and a type alias for
+// any other schema, with union types for oneOf and anyOf and literal types for enum and const
+type TypeScript struct{}
+
+func (TypeScript) Extension() string {
+	return ".d.ts"
+}
+
+func (ts TypeScript) Render(m *Module) ([]byte, error) {
+	var body strings.Builder
+	for _, d := range m.Declarations {
+		body.WriteString("\n")
+		body.WriteString(jsDoc(d.Schema, ""))
+		if isInterface(d.Schema) {
+			fmt.Fprintf(&body, "export interface %s %s\n", d.Name, ts.object(m, d.Schema, ""))
+		} else {
+			fmt.Fprintf(&body, "export type %s = %s;\n", d.Name, ts.expression(m, d.Schema, "", true))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by presidium-json codegen. DO NOT EDIT.\n")
+	if imports := m.Imports(); len(imports) > 0 {
+		b.WriteString("\n")
+		for _, i := range imports {
+			fmt.Fprintf(&b, "import type { %s } from \"./%s\";\n", strings.Join(i.Names, ", "), i.Module.Name)
+		}
+	}
+	b.WriteString(body.String())
+	return []byte(b.String()), nil
+}
+
+// isInterface returns whether s is declared as an interface rather than a type alias
+func isInterface(s *jsonschema.Schema) bool {
+	if s.Ref != nil || len(s.AllOf)+len(s.AnyOf)+len(s.OneOf) > 0 || s.Enum != nil || s.Constant != nil {
+		return false
+	}
+	return len(s.Properties) > 0 && (len(s.Types) == 0 || (len(s.Types) == 1 && s.Types[0] == "object"))
+}
+
+// expression returns the type of s, which is the declared type when declared is false and
+// s has a declaration
+func (ts TypeScript) expression(m *Module, s *jsonschema.Schema, indent string, declared bool) string {
+	if !declared {
+		if name, ok := m.Ref(s); ok {
+			return name
+		}
+	}
+	if s.Always != nil {
+		if *s.Always {
+			return "unknown"
+		}
+		return "never"
+	}
+
+	var parts []string
+	if s.Ref != nil {
+		parts = append(parts, ts.expression(m, s.Ref, indent, false))
+	}
+	for _, ref := range []*jsonschema.Schema{s.DynamicRef, s.RecursiveRef} {
+		if ref != nil {
+			parts = append(parts, ts.expression(m, ref, indent, false))
+		}
+	}
+	for _, sub := range s.AllOf {
+		parts = append(parts, ts.expression(m, sub, indent, false))
+	}
+	if len(s.OneOf)+len(s.AnyOf) > 0 {
+		var types []string
+		for _, sub := range append(append([]*jsonschema.Schema{}, s.OneOf...), s.AnyOf...) {
+			types = append(types, ts.expression(m, sub, indent, false))
+		}
+		parts = append(parts, strings.Join(unique(types), " | "))
+	}
+	if own := ts.own(m, s, indent); len(own) > 0 || len(parts) == 0 {
+		parts = append(parts, markdown.FirstNonEmpty(own, "unknown"))
+	}
+
+	parts = unique(parts)
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i, p := range parts {
+		parts[i] = parenthesize(p)
+	}
+	return strings.Join(parts, " & ")
+}
+
+// own returns the type of the keywords of s itself: its const, enum or types
+func (ts TypeScript) own(m *Module, s *jsonschema.Schema, indent string) string {
+	if len(s.Constant) > 0 {
+		return literal(s.Constant[0])
+	}
+	if s.Enum != nil {
+		var literals []string
+		for _, v := range s.Enum {
+			literals = append(literals, literal(v))
+		}
+		return strings.Join(unique(literals), " | ")
+	}
+
+	types := s.Types
+	if len(types) == 0 && (len(s.Properties) > 0 || len(s.PatternProperties) > 0 || markdown.AsSchema(markdown.AdditionalProperties(s)) != nil) {
+		types = []string{"object"}
+	}
+	var result []string
+	for _, t := range types {
+		switch t {
+		case "string", "boolean", "null":
+			result = append(result, t)
+		case "integer", "number":
+			result = append(result, "number")
+		case "array":
+			result = append(result, ts.array(m, s, indent))
+		case "object":
+			result = append(result, ts.object(m, s, indent))
+		}
+	}
+	return strings.Join(unique(result), " | ")
+}
+
+// array returns the type of an array: a tuple for prefix items, or an array of its items
+func (ts TypeScript) array(m *Module, s *jsonschema.Schema, indent string) string {
+	prefix, rest := s.PrefixItems, s.Items2020
+	switch items := s.Items.(type) {
+	case *jsonschema.Schema:
+		rest = items
+	case []*jsonschema.Schema:
+		prefix, rest = items, markdown.AsSchema(s.AdditionalItems)
+	}
+
+	if len(prefix) == 0 {
+		if rest == nil {
+			return "unknown[]"
+		}
+		return parenthesize(ts.expression(m, rest, indent, false)) + "[]"
+	}
+
+	var items []string
+	for _, p := range prefix {
+		items = append(items, ts.expression(m, p, indent, false))
+	}
+	if rest != nil {
+		items = append(items, "..."+ts.array(m, &jsonschema.Schema{Items2020: rest}, indent))
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// object returns the type literal of an object: a member for each property, optional unless
+// required, and an index signature for additional properties
+func (ts TypeScript) object(m *Module, s *jsonschema.Schema, indent string) string {
+	inner := indent + "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range PropertyNames(s) {
+		p := s.Properties[name]
+		b.WriteString(jsDoc(p, inner))
+
+		key := name
+		if !identifierRe.MatchString(name) {
+			key = literal(name)
+		}
+		optional := "?"
+		if IsRequired(s, name) {
+			optional = ""
+		}
+		fmt.Fprintf(&b, "%s%s%s: %s;\n", inner, key, optional, ts.expression(m, p, inner, false))
+	}
+
+	var additional []string
+	for _, p := range s.PatternProperties {
+		additional = append(additional, ts.expression(m, p, inner, false))
+	}
+	sort.Strings(additional)
+	if a := markdown.AsSchema(markdown.AdditionalProperties(s)); a != nil {
+		additional = append(additional, ts.expression(m, a, inner, false))
+	}
+	if len(additional) > 0 {
+		fmt.Fprintf(&b, "%s[key: string]: %s;\n", inner, strings.Join(unique(additional), " | "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// jsDoc returns the JSDoc comment of the description of s, marking deprecated schemas
+func jsDoc(s *jsonschema.Schema, indent string) string {
+	var lines []string
+	if len(s.Description) > 0 {
+		lines = strings.Split(strings.ReplaceAll(s.Description, "*/", "*\\/"), "\n")
+	}
+	if s.Deprecated {
+		lines = append(lines, "@deprecated")
+	}
+
+	switch len(lines) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%s/** %s */\n", indent, lines[0])
+	}
+	var b strings.Builder
+	b.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight(fmt.Sprintf("%s * %s", indent, line), " ") + "\n")
+	}
+	b.WriteString(indent + " */\n")
+	return b.String()
+}
+
+// literal returns the literal type of a json value
+func literal(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return "unknown"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "unknown"
+	}
+	return string(b)
+}
+
+// parenthesize wraps a union or intersection type in parentheses
+func parenthesize(t string) string {
+	depth, quoted := 0, false
+	for i, r := range t {
+		switch {
+		case quoted:
+			quoted = r != '"' || t[i-1] == '\\'
+		case r == '"':
+			quoted = true
+		case r == '{', r == '[', r == '(':
+			depth++
+		case r == '}', r == ']', r == ')':
+			depth--
+		case r == '|', r == '&':
+			if depth == 0 && i > 0 && t[i-1] == ' ' {
+				return "(" + t + ")"
+			}
+		}
+	}
+	return t
+}
+
+func unique(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if markdown.IndexOf(result, v) < 0 {
+			result = append(result, v)
+		}
+	}
+	return result
+}
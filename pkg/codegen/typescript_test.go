@@ -0,0 +1,52 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeScript_Render(t *testing.T) {
+	files := generate(t, TypeScript{})
+
+	order := files["order.d.ts"]
+	assert.Contains(t, order, `import type { Address, Customer } from "./customer";`)
+	assert.Contains(t, order, "/**\n * An order\n * placed by a customer\n */\nexport interface Order {\n")
+	assert.Contains(t, order, "  /** The id */\n  id: string;\n")
+	assert.Contains(t, order, `  status: "open" | "closed";`)
+	assert.Contains(t, order, `  kind?: "order";`)
+	assert.Contains(t, order, "  total?: number | null;")
+	assert.Contains(t, order, "  customer?: Customer;")
+	assert.Contains(t, order, "  lines?: Line[];")
+	assert.Contains(t, order, "  pair?: [string, number];")
+	assert.Contains(t, order, "  tags?: {\n    [key: string]: string;\n  };")
+	assert.Contains(t, order, "  \"x-meta\"?: {\n    a?: boolean;\n  };")
+	assert.Contains(t, order, "  payment?: Card | string;")
+	assert.Contains(t, order, "  codes?: (string | number)[];")
+	assert.Contains(t, order, "export interface Line {\n  /** @deprecated */\n  sku?: string;\n}")
+	assert.Contains(t, order, "export type Card = Address & {\n  number?: string;\n};")
+
+	customer := files["customer.d.ts"]
+	assert.NotContains(t, customer, "import")
+	assert.Contains(t, customer, "export interface Customer {\n  address?: Address;\n  name?: string;\n}")
+	assert.Contains(t, customer, "export interface Address {\n  city?: string;\n}")
+}
+
+func TestTypeScript_RenderModuleScope(t *testing.T) {
+	files := generateFiles(t, TypeScript{}, map[string]string{
+		"a.schema.json": `{"title": "Address", "type": "object", "properties": {"a": {"type": "string"}}}`,
+		"b.schema.json": `{"title": "Address", "type": "object", "properties": {"b": {"type": "string"}}}`,
+	})
+
+	// each TypeScript file is a module of its own
+	assert.Contains(t, files["a.d.ts"], "export interface Address {")
+	assert.Contains(t, files["b.d.ts"], "export interface Address {")
+}
+
+func TestParenthesize(t *testing.T) {
+	assert.Equal(t, "string", parenthesize("string"))
+	assert.Equal(t, "(string | number)", parenthesize("string | number"))
+	assert.Equal(t, "(A & B)", parenthesize("A & B"))
+	assert.Equal(t, "{\n  a: string | number;\n}", parenthesize("{\n  a: string | number;\n}"))
+	assert.Equal(t, `"a | b"`, parenthesize(`"a | b"`))
+}
@@ -0,0 +1,179 @@
+package coverage
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"text/template"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/SPANDigital/presidium-json-schema/templates"
+	"github.com/pkg/errors"
+)
+
+// Counts tallies how many schemas are documented with a description, examples and a title
+type Counts struct {
+	Total        int `json:"total"`
+	Descriptions int `json:"descriptions"`
+	Examples     int `json:"examples"`
+	Titles       int `json:"titles"`
+}
+
+func (c *Counts) add(s *markdown.Schema) bool {
+	c.Total++
+	if len(s.Examples) > 0 {
+		c.Examples++
+	}
+	if len(s.Title) > 0 {
+		c.Titles++
+	}
+	if len(s.Description) > 0 {
+		c.Descriptions++
+		return true
+	}
+	return false
+}
+
+func (c *Counts) merge(other Counts) {
+	c.Total += other.Total
+	c.Descriptions += other.Descriptions
+	c.Examples += other.Examples
+	c.Titles += other.Titles
+}
+
+// MarshalJSON adds the percentages to the counts, which are null when there is nothing to
+// count, like the "-" of the coverage page
+func (c Counts) MarshalJSON() ([]byte, error) {
+	type counts Counts
+	return json.Marshal(struct {
+		counts
+		DescriptionPercent *float64 `json:"descriptionPercent"`
+		ExamplePercent     *float64 `json:"examplePercent"`
+		TitlePercent       *float64 `json:"titlePercent"`
+	}{counts(c), percent(c.Descriptions, c.Total), percent(c.Examples, c.Total), percent(c.Titles, c.Total)})
+}
+
+// percent returns n out of total as a percentage, or nil when total is zero
+func percent(n, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	p := float64(n) * 100 / float64(total)
+	return &p
+}
+
+// File is the documentation coverage of a schema file
+type File struct {
+	File         string   `json:"file"`
+	Properties   Counts   `json:"properties"`
+	Definitions  Counts   `json:"definitions"`
+	Undocumented []string `json:"undocumented"`
+}
+
+// Report is the documentation coverage of each schema file and overall
+type Report struct {
+	Files []*File `json:"files"`
+	Total File    `json:"total"`
+}
+
+// Measure computes the documentation coverage of the properties and definitions of the
+// loaded schemas. Schemas outside the loaded files, e.g. remote references, are ignored.
+func Measure(schemas []*markdown.Schema) *Report {
+	documents := map[string]bool{}
+	for _, s := range schemas {
+		documents[markdown.TrimAnchorPath(s.Location)] = true
+	}
+	local := func(s *markdown.Schema) bool {
+		return documents[markdown.TrimAnchorPath(s.Location)]
+	}
+
+	files := map[string]*File{}
+	fileOf := func(s *markdown.Schema) *File {
+		path := markdown.FileFromURL(markdown.TrimAnchorPath(s.Location))
+		if _, ok := files[path]; !ok {
+			files[path] = &File{File: path, Undocumented: []string{}}
+		}
+		return files[path]
+	}
+
+	for _, root := range schemas {
+		fileOf(root)
+	}
+
+	visited := map[string]bool{}
+	for _, root := range schemas {
+		root.WalkSchema(true, func(s *markdown.Schema) error {
+			if visited[s.Location] || !local(s) {
+				return nil
+			}
+			visited[s.Location] = true
+
+			for _, prop := range s.Properties {
+				p := markdown.ToSchema(prop, s.Path)
+				// a reference is documented by the definition it points to
+				if prop.Ref != nil && len(prop.Description) == 0 {
+					p = markdown.ToSchema(prop.Ref, s.Path)
+				}
+				f := fileOf(markdown.ToSchema(prop, s.Path))
+				if !f.Properties.add(p) {
+					f.Undocumented = append(f.Undocumented, markdown.Pointer(prop.Location))
+				}
+			}
+			return nil
+		})
+	}
+
+	definitions := map[string]bool{}
+	for _, root := range schemas {
+		definitions[root.Location] = true
+	}
+	for _, root := range schemas {
+		for _, def := range root.Definitions() {
+			if definitions[def.Location] || !local(def) {
+				continue
+			}
+			definitions[def.Location] = true
+
+			f := fileOf(def)
+			if !f.Definitions.add(def) {
+				f.Undocumented = append(f.Undocumented, markdown.Pointer(def.Location))
+			}
+		}
+	}
+
+	report := &Report{Total: File{File: "total", Undocumented: []string{}}}
+	for _, f := range files {
+		sort.Strings(f.Undocumented)
+		report.Files = append(report.Files, f)
+		report.Total.Properties.merge(f.Properties)
+		report.Total.Definitions.merge(f.Definitions)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].File < report.Files[j].File
+	})
+	return report
+}
+
+// WriteJSON writes the report as a json summary
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// coverageTitle is the title of the coverage page
+const coverageTitle = "Documentation Coverage"
+
+// WriteMarkdown writes the report as a Presidium page, whose front matter is written like
+// the front matter of the schema pages
+func (r *Report) WriteMarkdown(w io.Writer, fm markdown.FrontMatter) error {
+	funcs := markdown.FuncMap("", nil, nil)
+	funcs["frontMatter"] = func() (string, error) {
+		return fm.Render(fm.PageFields(coverageTitle, 0, "", nil))
+	}
+	t, err := template.New("").Funcs(funcs).ParseFS(templates.Files, "coverage.gohtml")
+	if err != nil {
+		return errors.Wrap(err, "failed to parse templates")
+	}
+	return t.ExecuteTemplate(w, "coverage", r)
+}
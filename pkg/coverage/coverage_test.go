@@ -0,0 +1,84 @@
+package coverage
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const schema = `{
+  "title": "Order",
+  "type": "object",
+  "properties": {
+    "id": { "type": "string", "description": "The id", "examples": ["a1"] },
+    "note": { "type": "string" },
+    "customer": { "$ref": "#/$defs/customer" }
+  },
+  "$defs": {
+    "customer": {
+      "description": "A customer",
+      "type": "object",
+      "properties": { "name": { "type": "string", "title": "Name" } }
+    }
+  }
+}`
+
+func measure(t *testing.T) *Report {
+	markdown.AppFS = afero.NewMemMapFs()
+	assert.Nil(t, afero.WriteFile(markdown.AppFS, "/coverage/order.schema.json", []byte(schema), os.ModePerm))
+
+	c := markdown.NewConverter(markdown.Config{Extension: "*.schema.json"})
+	schemas, err := c.Load("/coverage")
+	assert.Nil(t, err)
+	return Measure(schemas)
+}
+
+func TestMeasure(t *testing.T) {
+	report := measure(t)
+	assert.Len(t, report.Files, 1)
+
+	f := report.Files[0]
+	assert.Equal(t, "/coverage/order.schema.json", f.File)
+	assert.Equal(t, Counts{Total: 4, Descriptions: 2, Examples: 1, Titles: 1}, f.Properties)
+	assert.Equal(t, Counts{Total: 1, Descriptions: 1}, f.Definitions)
+	assert.Equal(t, []string{"/$defs/customer/properties/name", "/properties/note"}, f.Undocumented)
+	assert.Equal(t, f.Properties, report.Total.Properties)
+}
+
+func TestReport_Write(t *testing.T) {
+	report := measure(t)
+
+	var md bytes.Buffer
+	assert.Nil(t, report.WriteMarkdown(&md, markdown.FrontMatter{}))
+	assert.True(t, strings.HasPrefix(md.String(), "---\ntitle: Documentation Coverage\n---\n"))
+	assert.Contains(t, md.String(), "| order.schema.json | 4 | 50.0% | 25.0% | 25.0% | 1 | 100.0% | 0.0% | 0.0% |")
+	assert.Contains(t, md.String(), "- `/properties/note`")
+
+	var js bytes.Buffer
+	assert.Nil(t, report.WriteJSON(&js))
+	assert.Contains(t, js.String(), `"descriptionPercent": 50`)
+
+	// the front matter follows the configured format and page keys
+	md.Reset()
+	fm := markdown.FrontMatter{Format: markdown.FrontMatterTOML, Pages: map[string]interface{}{"draft": true}}
+	assert.Nil(t, report.WriteMarkdown(&md, fm))
+	assert.True(t, strings.HasPrefix(md.String(), "+++\ntitle = \"Documentation Coverage\"\ndraft = true\n+++\n"))
+}
+
+func TestCounts_MarshalJSON(t *testing.T) {
+	// nothing to count has no percentage, like the "-" of the coverage page
+	b, err := json.Marshal(Counts{})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"total": 0, "descriptions": 0, "examples": 0, "titles": 0, "descriptionPercent": null, "examplePercent": null, "titlePercent": null}`, string(b))
+	assert.Equal(t, "-", markdown.Percent(0, 0))
+
+	b, err = json.Marshal(Counts{Total: 4, Descriptions: 1})
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"descriptionPercent":25,"examplePercent":0`)
+}
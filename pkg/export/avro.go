@@ -0,0 +1,287 @@
+package export
+
+import (
+	"encoding/json"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/codegen"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// the logical types of the string formats Avro represents
+var avroFormats = map[string]avroLogical{
+	"date":      {Type: "int", LogicalType: "date"},
+	"time":      {Type: "int", LogicalType: "time-millis"},
+	"date-time": {Type: "long", LogicalType: "timestamp-millis"},
+	"uuid":      {Type: "string", LogicalType: "uuid"},
+}
+
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Doc       string      `json:"doc,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+type avroField struct {
+	Name    string          `json:"name"`
+	Doc     string          `json:"doc,omitempty"`
+	Type    interface{}     `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+type avroEnum struct {
+	Type      string   `json:"type"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	Doc       string   `json:"doc,omitempty"`
+	Symbols   []string `json:"symbols"`
+}
+
+type avroArray struct {
+	Type  string      `json:"type"`
+	Items interface{} `json:"items"`
+}
+
+type avroMap struct {
+	Type   string      `json:"type"`
+	Values interface{} `json:"values"`
+}
+
+type avroLogical struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+}
+
+// Avro exports schemas to .avsc files: a record for each object, an enum for each enum of
+// strings, an array or map for arrays and additional properties and a union for oneOf and
+// anyOf, with optional fields being unions with null
+type Avro struct{}
+
+func (Avro) Extension() string {
+	return ".avsc"
+}
+
+func (Avro) Render(m *codegen.Module) ([]byte, error) {
+	r := &avroRenderer{module: m, defined: map[string]bool{}}
+	var schemas []interface{}
+	for i, d := range m.Declarations {
+		// the types other than records and enums are inlined where they are referenced
+		if r.defined[d.Name] || (i > 0 && !isRecord(d.Schema) && !isEnum(d.Schema)) {
+			continue
+		}
+		// the named types at the top of the file declare its namespace
+		switch schema := r.schema(d.Name, d.Schema, true).(type) {
+		case avroRecord:
+			schema.Namespace = namespace(m)
+			schemas = append(schemas, schema)
+		case avroEnum:
+			schema.Namespace = namespace(m)
+			schemas = append(schemas, schema)
+		default:
+			schemas = append(schemas, schema)
+		}
+	}
+
+	var v interface{} = schemas
+	if len(schemas) == 1 {
+		v = schemas[0]
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// avroRenderer renders the declarations of a module, defining each named type on its first
+// use and referencing it by name afterwards
+type avroRenderer struct {
+	module  *codegen.Module
+	defined map[string]bool
+}
+
+// schema returns the Avro schema of s, which is the declared type when declared is false
+// and s has a declaration. Records and enums nested in s are named after name.
+func (r *avroRenderer) schema(name string, s *jsonschema.Schema, declared bool) interface{} {
+	if !declared && (isRecord(s) || isEnum(s)) {
+		if d, ok := r.module.Declaration(s); ok {
+			if d.Module != r.module {
+				return namespace(d.Module) + "." + d.Name
+			}
+			if r.defined[d.Name] {
+				return d.Name
+			}
+			name = d.Name
+		} else {
+			name = r.module.Reserve(name)
+		}
+	}
+	warnUnsupported(r.module, s, "Avro")
+
+	if s.Always != nil {
+		r.module.Warn(s, "a schema allowing any value cannot be represented in Avro, it is exported as a string")
+		return "string"
+	}
+	if s.Ref != nil && len(s.AllOf) == 0 && len(s.Properties) == 0 && len(s.Types) == 0 {
+		return r.schema(name, s.Ref, false)
+	}
+	if branches := union(s); len(branches) > 0 {
+		var types []interface{}
+		for _, b := range branches {
+			types = appendUnion(types, r.schema(name, b, false))
+		}
+		return types
+	}
+
+	switch {
+	case isRecord(s):
+		return r.record(name, s)
+	case isEnum(s):
+		return r.enum(name, s)
+	case len(s.Constant) > 0:
+		return avroValueType(s.Constant[0])
+	case len(s.Enum) > 0:
+		r.module.Warn(s, "an enum of other values than strings cannot be represented in Avro")
+		return avroValueType(s.Enum[0])
+	}
+
+	types := s.Types
+	if len(types) == 0 && markdown.AsSchema(markdown.AdditionalProperties(s)) != nil {
+		types = []string{"object"}
+	}
+	var result []interface{}
+	for _, t := range types {
+		result = appendUnion(result, r.primitive(name, s, t))
+	}
+	switch len(result) {
+	case 0:
+		r.module.Warn(s, "a schema without a type cannot be represented in Avro, it is exported as a string")
+		return "string"
+	case 1:
+		return result[0]
+	}
+	return result
+}
+
+// primitive returns the Avro schema of the values of s of type t
+func (r *avroRenderer) primitive(name string, s *jsonschema.Schema, t string) interface{} {
+	switch t {
+	case "null", "string", "boolean":
+		if t == "string" && len(s.Format) > 0 {
+			if logical, ok := avroFormats[s.Format]; ok {
+				return logical
+			}
+			r.module.Warn(s, "format %s cannot be represented in Avro", s.Format)
+		}
+		return t
+	case "integer":
+		return "long"
+	case "number":
+		return "double"
+	case "array":
+		items, tuple := items(s)
+		if tuple {
+			r.module.Warn(s, "tuples cannot be represented in Avro, their items are exported as strings")
+			return avroArray{Type: "array", Items: "string"}
+		}
+		if items == nil {
+			r.module.Warn(s, "an array without items cannot be represented in Avro, its items are exported as strings")
+			return avroArray{Type: "array", Items: "string"}
+		}
+		return avroArray{Type: "array", Items: r.schema(name+"Item", items, false)}
+	default:
+		if additional := markdown.AsSchema(markdown.AdditionalProperties(s)); additional != nil {
+			return avroMap{Type: "map", Values: r.schema(name+"Value", additional, false)}
+		}
+		r.module.Warn(s, "an object without properties cannot be represented in Avro, its values are exported as strings")
+		return avroMap{Type: "map", Values: "string"}
+	}
+}
+
+// record returns the record of an object, whose optional fields are unions with null
+// defaulting to null
+func (r *avroRenderer) record(name string, s *jsonschema.Schema) avroRecord {
+	r.defined[name] = true
+	record := avroRecord{Type: "record", Name: name, Doc: s.Description, Fields: []avroField{}}
+
+	props, required := properties(s)
+	for _, property := range sortedNames(props) {
+		p := props[property]
+		field := avroField{Name: r.name(p, property), Doc: p.Description}
+		field.Type = r.schema(name+codegen.PascalCase(property), p, false)
+		if !isRequired(required, property) {
+			field.Type = appendUnion([]interface{}{"null"}, field.Type)
+			field.Default = json.RawMessage("null")
+		}
+		record.Fields = append(record.Fields, field)
+	}
+	return record
+}
+
+// enum returns the enum of an enum of strings
+func (r *avroRenderer) enum(name string, s *jsonschema.Schema) interface{} {
+	var symbols []string
+	for _, v := range s.Enum {
+		symbol := v.(string)
+		if !nameRe.MatchString(symbol) {
+			r.module.Warn(s, "enum value %q is not a valid Avro symbol, the enum is exported as a string", symbol)
+			return "string"
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	r.defined[name] = true
+	return avroEnum{Type: "enum", Name: name, Doc: s.Description, Symbols: symbols}
+}
+
+// name returns the Avro name of a property, replacing the characters names cannot hold
+func (r *avroRenderer) name(s *jsonschema.Schema, property string) string {
+	if nameRe.MatchString(property) {
+		return property
+	}
+	name := invalidNameRe.ReplaceAllString(property, "_")
+	if !nameRe.MatchString(name) {
+		name = "_" + name
+	}
+	r.module.Warn(s, "property %s is not a valid Avro name, it is exported as %s", property, name)
+	return name
+}
+
+// appendUnion appends a schema to a union, flattening nested unions and leaving out the
+// types already in the union
+func appendUnion(union []interface{}, schema interface{}) []interface{} {
+	if nested, ok := schema.([]interface{}); ok {
+		for _, s := range nested {
+			union = appendUnion(union, s)
+		}
+		return union
+	}
+	key, _ := json.Marshal(schema)
+	for _, s := range union {
+		if existing, _ := json.Marshal(s); string(existing) == string(key) {
+			return union
+		}
+	}
+	return append(union, schema)
+}
+
+// avroValueType returns the Avro type of a json value
+func avroValueType(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "long"
+		}
+		return "double"
+	case nil:
+		return "null"
+	default:
+		return "string"
+	}
+}
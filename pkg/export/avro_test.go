@@ -0,0 +1,48 @@
+package export
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvro_Render(t *testing.T) {
+	files, warnings := generate(t, Avro{})
+
+	var order map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(files["order.avsc"]), &order))
+	assert.Equal(t, "record", order["type"])
+	assert.Equal(t, "Order", order["name"])
+	assert.Equal(t, "order", order["namespace"])
+	assert.Equal(t, "An order", order["doc"])
+
+	fields := map[string]interface{}{}
+	for _, f := range order["fields"].([]interface{}) {
+		field := f.(map[string]interface{})
+		fields[field["name"].(string)] = field["type"]
+	}
+	assert.Equal(t, "string", fields["id"])
+	assert.Equal(t, map[string]interface{}{"type": "enum", "name": "OrderStatus", "symbols": []interface{}{"open", "closed"}}, fields["status"])
+	assert.Equal(t, []interface{}{"null", "long"}, fields["quantity"])
+	assert.Equal(t, []interface{}{"null", map[string]interface{}{"type": "long", "logicalType": "timestamp-millis"}}, fields["placedAt"])
+	assert.Equal(t, []interface{}{"null", "customer.Customer"}, fields["customer"])
+	assert.Equal(t, []interface{}{"null", map[string]interface{}{"type": "map", "values": "string"}}, fields["tags"])
+	assert.Contains(t, fields, "x_meta")
+
+	lines := fields["lines"].([]interface{})[1].(map[string]interface{})
+	assert.Equal(t, "array", lines["type"])
+	assert.Equal(t, "Line", lines["items"].(map[string]interface{})["name"])
+
+	payment := fields["payment"].([]interface{})
+	assert.Len(t, payment, 3)
+	assert.Equal(t, "Card", payment[1].(map[string]interface{})["name"])
+	assert.Equal(t, "string", payment[2])
+
+	assert.Contains(t, files["customer.avsc"], `"namespace": "customer"`)
+	assert.Equal(t, []string{
+		"/export/order.schema.json#/properties/id: pattern cannot be represented in Avro",
+		"/export/order.schema.json#/properties/quantity: minimum cannot be represented in Avro",
+		"/export/order.schema.json#/properties/x-meta: property x-meta is not a valid Avro name, it is exported as x_meta",
+	}, warnings)
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/codegen"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var nameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+var invalidNameRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Formats are the formats schemas are exported to, by name
+var Formats = map[string]codegen.Language{
+	"avro":  Avro{},
+	"proto": Proto{},
+}
+
+// FindFormat returns the format named name
+func FindFormat(name string) (codegen.Language, error) {
+	if format, ok := Formats[name]; ok {
+		return format, nil
+	}
+	var names []string
+	for n := range Formats {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf(`invalid format "%s", expected one of %s`, name, strings.Join(names, ", "))
+}
+
+// warnUnsupported warns about the keywords of s the format cannot represent, such as patterns
+// and numeric bounds
+func warnUnsupported(m *codegen.Module, s *jsonschema.Schema, format string) {
+	keywords := []struct {
+		name    string
+		present bool
+	}{
+		{"pattern", s.Pattern != nil},
+		{"patternProperties", len(s.PatternProperties) > 0},
+		{"minimum", s.Minimum != nil},
+		{"maximum", s.Maximum != nil},
+		{"exclusiveMinimum", s.ExclusiveMinimum != nil},
+		{"exclusiveMaximum", s.ExclusiveMaximum != nil},
+		{"multipleOf", s.MultipleOf != nil},
+		{"minLength", s.MinLength > 0},
+		{"maxLength", s.MaxLength >= 0},
+		{"minItems", s.MinItems > 0},
+		{"maxItems", s.MaxItems >= 0},
+		{"uniqueItems", s.UniqueItems},
+		{"contains", s.Contains != nil},
+		{"minProperties", s.MinProperties > 0},
+		{"maxProperties", s.MaxProperties >= 0},
+		{"propertyNames", s.PropertyNames != nil},
+		{"dependentRequired", len(s.DependentRequired) > 0},
+		{"dependentSchemas", len(s.DependentSchemas) > 0},
+		{"const", len(s.Constant) > 0},
+		{"not", s.Not != nil},
+		{"if", s.If != nil},
+	}
+	for _, k := range keywords {
+		if k.present {
+			m.Warn(s, "%s cannot be represented in %s", k.name, format)
+		}
+	}
+}
+
+// properties returns the properties of s merged with those of the branches of its allOf
+// composition, along with the names of the required properties
+func properties(s *jsonschema.Schema) (map[string]*jsonschema.Schema, []string) {
+	props := map[string]*jsonschema.Schema{}
+	var required []string
+	for _, sub := range s.AllOf {
+		p, r := properties(codegen.Target(sub))
+		for name, schema := range p {
+			props[name] = schema
+		}
+		required = append(required, r...)
+	}
+	for name, schema := range s.Properties {
+		props[name] = schema
+	}
+	return props, append(required, s.Required...)
+}
+
+// propertyOrder returns the names of the properties of s and of the branches of its allOf
+// composition in the order they are declared, or nil when order does not know it
+func propertyOrder(s *jsonschema.Schema, order func(s *jsonschema.Schema) []string) []string {
+	var names []string
+	for _, sub := range s.AllOf {
+		sub = codegen.Target(sub)
+		if len(sub.Properties)+len(sub.AllOf) == 0 {
+			continue
+		}
+		n := propertyOrder(sub, order)
+		if n == nil {
+			return nil
+		}
+		names = append(names, n...)
+	}
+	if len(s.Properties) > 0 {
+		n := order(s)
+		if len(n) != len(s.Properties) {
+			return nil
+		}
+		names = append(names, n...)
+	}
+
+	var result []string
+	for _, name := range names {
+		if markdown.IndexOf(result, name) < 0 {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// isRecord returns whether s is exported as a record or message: an object with properties,
+// or the composition of such objects
+func isRecord(s *jsonschema.Schema) bool {
+	if len(s.AnyOf)+len(s.OneOf) > 0 || s.Enum != nil {
+		return false
+	}
+	if types := codegen.NonNull(s.Types); len(types) > 1 || (len(types) == 1 && types[0] != "object") {
+		return false
+	}
+	if len(s.Properties) > 0 {
+		return true
+	}
+	if len(s.AllOf) == 0 {
+		return false
+	}
+	for _, sub := range s.AllOf {
+		if !isRecord(codegen.Target(sub)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isEnum returns whether s is exported as an enum: an enum of strings
+func isEnum(s *jsonschema.Schema) bool {
+	if len(s.Enum) == 0 {
+		return false
+	}
+	for _, v := range s.Enum {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// union returns the branches of the oneOf and anyOf compositions of s
+func union(s *jsonschema.Schema) []*jsonschema.Schema {
+	return append(append([]*jsonschema.Schema{}, s.OneOf...), s.AnyOf...)
+}
+
+// items returns the schema of the items of an array, and whether it is a tuple
+func items(s *jsonschema.Schema) (*jsonschema.Schema, bool) {
+	switch i := s.Items.(type) {
+	case *jsonschema.Schema:
+		return i, false
+	case []*jsonschema.Schema:
+		return nil, true
+	}
+	return s.Items2020, len(s.PrefixItems) > 0
+}
+
+// namespace returns the namespace or package of the types of a module, e.g. user_profile for
+// user-profile.schema.json
+func namespace(m *codegen.Module) string {
+	name := invalidNameRe.ReplaceAllString(m.Name, "_")
+	if !nameRe.MatchString(name) {
+		name = "_" + name
+	}
+	return name
+}
+
+// sortedNames returns the names of the properties in alphabetical order
+func sortedNames(props map[string]*jsonschema.Schema) []string {
+	var names []string
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isRequired(required []string, name string) bool {
+	return markdown.IndexOf(required, name) >= 0
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"os"
+	"testing"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/codegen"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const orderSchema = `{
+  "title": "Order",
+  "description": "An order",
+  "type": "object",
+  "required": ["id", "status"],
+  "properties": {
+    "id": { "type": "string", "description": "The id", "pattern": "^[a-z0-9]+$" },
+    "status": { "enum": ["open", "closed"] },
+    "quantity": { "type": "integer", "minimum": 1 },
+    "placedAt": { "type": "string", "format": "date-time" },
+    "customer": { "$ref": "customer.schema.json" },
+    "lines": { "type": "array", "items": { "$ref": "#/$defs/line" } },
+    "tags": { "type": "object", "additionalProperties": { "type": "string" } },
+    "x-meta": { "type": "object", "properties": { "a": { "type": "boolean" } } },
+    "payment": { "oneOf": [{ "$ref": "#/$defs/card" }, { "type": "string" }] }
+  },
+  "$defs": {
+    "line": { "type": "object", "properties": { "sku": { "type": "string" } } },
+    "card": { "type": "object", "properties": { "number": { "type": "string" } } }
+  }
+}`
+
+const customerSchema = `{
+  "title": "Customer",
+  "type": "object",
+  "properties": { "name": { "type": "string" } }
+}`
+
+// generate exports the order and customer schemas to format
+func generate(t *testing.T, format codegen.Language) (map[string]string, []string) {
+	documents := map[string]string{"order.schema.json": orderSchema, "customer.schema.json": customerSchema}
+	return generateWith(t, documents, func(*markdown.Converter) codegen.Language { return format })
+}
+
+// generateWith exports the documents, loaded with the order of their keys, to the format of
+// the converter loading them
+func generateWith(t *testing.T, documents map[string]string, format func(c *markdown.Converter) codegen.Language) (map[string]string, []string) {
+	markdown.AppFS = afero.NewMemMapFs()
+	for name, document := range documents {
+		assert.Nil(t, afero.WriteFile(markdown.AppFS, "/export/"+name, []byte(document), os.ModePerm))
+	}
+
+	c := markdown.NewConverter(markdown.Config{Extension: "*.schema.json", Ordered: true})
+	schemas, err := c.Load("/export")
+	assert.Nil(t, err)
+
+	g := codegen.NewGenerator(format(c))
+	files, err := g.Generate(schemas)
+	assert.Nil(t, err)
+	result := map[string]string{}
+	for _, f := range files {
+		result[f.Path] = string(f.Content)
+	}
+	var warnings []string
+	for _, w := range g.Warnings() {
+		warnings = append(warnings, w.String())
+	}
+	return result, warnings
+}
+
+func TestFindFormat(t *testing.T) {
+	format, err := FindFormat("proto")
+	assert.Nil(t, err)
+	assert.Equal(t, Proto{}, format)
+
+	_, err = FindFormat("thrift")
+	assert.EqualError(t, err, `invalid format "thrift", expected one of avro, proto`)
+}
@@ -0,0 +1,427 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/codegen"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/iancoleman/strcase"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// the well-known types of the values protocol buffers cannot type more precisely
+const (
+	protoValue     = "google.protobuf.Value"
+	protoListValue = "google.protobuf.ListValue"
+	protoTimestamp = "google.protobuf.Timestamp"
+)
+
+// the files declaring the well-known types
+var protoImports = map[string]string{
+	protoValue:     "google/protobuf/struct.proto",
+	protoListValue: "google/protobuf/struct.proto",
+	protoTimestamp: "google/protobuf/timestamp.proto",
+}
+
+// ProtoField is the keyword fixing the number of the field of a property, or of a branch of
+// a oneOf or anyOf composition
+const ProtoField = "x-proto-field"
+
+// the largest field number, and the range of field numbers reserved by protocol buffers
+const (
+	protoMaxField      = 536870911
+	protoReservedFirst = 19000
+	protoReservedLast  = 19999
+)
+
+// Proto exports schemas to proto3 .proto files: a message for each object, an enum for each
+// enum of strings, repeated fields for arrays, maps for additional properties and a oneof
+// for oneOf, anyOf and properties of several types
+type Proto struct {
+	// Converter loaded the schemas, it looks up the declared order of the properties, which
+	// numbers the fields, and their x-proto-field keyword. Without it, the fields are numbered
+	// in alphabetical order.
+	Converter *markdown.Converter
+}
+
+func (Proto) Extension() string {
+	return ".proto"
+}
+
+func (p Proto) Render(m *codegen.Module) ([]byte, error) {
+	r := &protoRenderer{module: m, imports: map[string]bool{}, converter: p.Converter}
+	for i, d := range m.Declarations {
+		switch {
+		case isRecord(d.Schema) || isEnum(d.Schema) || len(union(d.Schema)) > 0:
+			r.declare(d.Name, d.Schema)
+		case i == 0:
+			// a root schema which is not an object is wrapped in a message
+			m.Warn(d.Schema, "a root schema which is not an object is exported as a message with a value field")
+			r.message(d.Name, d.Schema, map[string]*jsonschema.Schema{"value": d.Schema}, []string{"value"})
+		}
+		// the other types are inlined where they are referenced
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by presidium-json export. DO NOT EDIT.\n\n")
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n", namespace(m))
+
+	imports := r.imports
+	for _, i := range m.Imports() {
+		imports[i.Module.Name+".proto"] = true
+	}
+	if len(imports) > 0 {
+		var files []string
+		for file := range imports {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		b.WriteString("\n")
+		for _, file := range files {
+			fmt.Fprintf(&b, "import %q;\n", file)
+		}
+	}
+	b.WriteString(r.body.String())
+	return []byte(b.String()), nil
+}
+
+// protoRenderer renders the declarations of a module, along with the messages and enums
+// nested in them
+type protoRenderer struct {
+	module    *codegen.Module
+	body      strings.Builder
+	imports   map[string]bool
+	pending   []*codegen.Declaration
+	converter *markdown.Converter
+}
+
+// protoField is a field of a message: a property, or a branch of the oneof of a property
+type protoField struct {
+	property string
+	schema   *jsonschema.Schema
+	// typed is set for the branches of a property of several types, which are numbered by
+	// their position as they share the x-proto-field keyword of the property
+	typed bool
+}
+
+// declare writes the message or enum name for s, followed by the types nested in it
+func (r *protoRenderer) declare(name string, s *jsonschema.Schema) {
+	pending := r.pending
+	r.pending = nil
+
+	switch {
+	case isEnum(s):
+		r.enum(name, s)
+	case len(union(s)) > 0:
+		r.message(name, s, map[string]*jsonschema.Schema{"value": s}, nil)
+	default:
+		props, required := properties(s)
+		r.message(name, s, props, required)
+	}
+
+	nested := r.pending
+	r.pending = pending
+	for _, d := range nested {
+		r.declare(d.Name, d.Schema)
+	}
+}
+
+// message writes a message with a field for each property and a oneof for each property
+// holding a oneOf or anyOf composition or having several types, the fields being numbered by
+// their x-proto-field keyword or their declared order
+func (r *protoRenderer) message(name string, s *jsonschema.Schema, props map[string]*jsonschema.Schema, required []string) {
+	warnUnsupported(r.module, s, "protocol buffers")
+
+	names, ordered := r.propertyNames(s, props)
+	var fields []protoField
+	for _, property := range names {
+		branches, typed := oneofBranches(props[property])
+		if len(branches) == 0 {
+			branches = []*jsonschema.Schema{props[property]}
+		}
+		for _, b := range branches {
+			fields = append(fields, protoField{property, b, typed})
+		}
+	}
+	numbers := r.numbers(name, s, fields, ordered)
+
+	r.body.WriteString("\n")
+	r.body.WriteString(protoDoc(s.Description, ""))
+	fmt.Fprintf(&r.body, "message %s {\n", name)
+	n := 0
+	for _, property := range names {
+		p := props[property]
+		field := strcase.ToSnake(property)
+		option := ""
+		if field != property {
+			option = fmt.Sprintf(" [json_name = %q]", property)
+		}
+
+		if branches, _ := oneofBranches(p); len(branches) > 0 {
+			r.body.WriteString(protoDoc(p.Description, "  "))
+			fmt.Fprintf(&r.body, "  oneof %s {\n", field)
+			names := map[string]bool{}
+			for _, b := range branches {
+				t := r.element(b, r.fieldType(name+codegen.PascalCase(property), b))
+				branch := field + "_" + strcase.ToSnake(t[strings.LastIndex(t, ".")+1:])
+				for i := 2; names[branch]; i++ {
+					branch = fmt.Sprintf("%s_%s_%d", field, strcase.ToSnake(t[strings.LastIndex(t, ".")+1:]), i)
+				}
+				names[branch] = true
+				fmt.Fprintf(&r.body, "    %s %s = %d;\n", t, branch, numbers[n])
+				n++
+			}
+			r.body.WriteString("  }\n")
+			continue
+		}
+
+		r.body.WriteString(protoDoc(p.Description, "  "))
+		t := r.fieldType(name+codegen.PascalCase(property), p)
+		if !isRequired(required, property) && isScalar(t) {
+			t = "optional " + t
+		}
+		fmt.Fprintf(&r.body, "  %s %s = %d%s;\n", t, field, numbers[n], option)
+		n++
+	}
+	r.body.WriteString("}\n")
+}
+
+// propertyNames returns the names of the properties of a message in the order they are
+// declared, or in alphabetical order when that order is unknown
+func (r *protoRenderer) propertyNames(s *jsonschema.Schema, props map[string]*jsonschema.Schema) ([]string, bool) {
+	if r.converter != nil {
+		if names := propertyOrder(s, r.converter.PropertyOrder); len(names) == len(props) {
+			return names, true
+		}
+	}
+	return sortedNames(props), false
+}
+
+// numbers returns the numbers of the fields of a message: the number set by the x-proto-field
+// keyword of a field, or the next free number in the order of the fields otherwise
+func (r *protoRenderer) numbers(name string, s *jsonschema.Schema, fields []protoField, ordered bool) []int {
+	numbers := make([]int, len(fields))
+	used := map[int]bool{}
+	for i, f := range fields {
+		n, ok := r.fieldNumber(f)
+		if !ok {
+			continue
+		}
+		if used[n] {
+			r.module.Warn(f.schema, "field number %d of %s is already used, the field is numbered after its position", n, f.property)
+			continue
+		}
+		used[n], numbers[i] = true, n
+	}
+
+	next := 1
+	var unfixed []string
+	for i, f := range fields {
+		if numbers[i] > 0 {
+			continue
+		}
+		for used[next] || (next >= protoReservedFirst && next <= protoReservedLast) {
+			next++
+		}
+		used[next], numbers[i] = true, next
+		if markdown.IndexOf(unfixed, f.property) < 0 {
+			unfixed = append(unfixed, f.property)
+		}
+	}
+	if !ordered && len(unfixed) > 1 {
+		r.module.Warn(s, "the declared order of the properties of %s is unknown, fields %s are numbered in alphabetical order", name, strings.Join(unfixed, ", "))
+	}
+	return numbers
+}
+
+// fieldNumber returns the number the x-proto-field keyword of a field sets
+func (r *protoRenderer) fieldNumber(f protoField) (int, bool) {
+	if r.converter == nil || f.typed {
+		return 0, false
+	}
+	v, ok := r.converter.Keyword(f.schema, ProtoField)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(fmt.Sprint(v))
+	if err != nil || n < 1 || n > protoMaxField || (n >= protoReservedFirst && n <= protoReservedLast) {
+		r.module.Warn(f.schema, "%s %v of %s is not a valid field number", ProtoField, v, f.property)
+		return 0, false
+	}
+	return n, true
+}
+
+// enum writes an enum whose values are prefixed by its name in upper snake case, after the
+// zero value proto3 requires
+func (r *protoRenderer) enum(name string, s *jsonschema.Schema) {
+	warnUnsupported(r.module, s, "protocol buffers")
+	prefix := strcase.ToScreamingSnake(name)
+
+	r.body.WriteString("\n")
+	r.body.WriteString(protoDoc(s.Description, ""))
+	fmt.Fprintf(&r.body, "enum %s {\n", name)
+	fmt.Fprintf(&r.body, "  %s_UNSPECIFIED = 0;\n", prefix)
+	for i, v := range s.Enum {
+		value := strcase.ToScreamingSnake(invalidNameRe.ReplaceAllString(v.(string), "_"))
+		if len(value) == 0 {
+			value = fmt.Sprintf("VALUE_%d", i+1)
+		}
+		fmt.Fprintf(&r.body, "  %s_%s = %d;\n", prefix, value, i+1)
+	}
+	r.body.WriteString("}\n")
+}
+
+// fieldType returns the type of a field holding s, declaring the messages and enums nested in
+// s as types named after name
+func (r *protoRenderer) fieldType(name string, s *jsonschema.Schema) string {
+	if isRecord(s) || isEnum(s) {
+		if d, ok := r.module.Declaration(s); ok {
+			if d.Module != r.module {
+				return namespace(d.Module) + "." + d.Name
+			}
+			return d.Name
+		}
+		name = r.module.Reserve(name)
+		r.pending = append(r.pending, &codegen.Declaration{Name: name, Schema: s, Module: r.module})
+		return name
+	}
+	warnUnsupported(r.module, s, "protocol buffers")
+
+	if s.Ref != nil && len(s.AllOf) == 0 && len(s.Types) == 0 {
+		return r.fieldType(name, s.Ref)
+	}
+	if len(union(s)) > 0 {
+		r.module.Warn(s, "a nested oneOf or anyOf cannot be represented in protocol buffers, it is exported as a %s", protoValue)
+		return r.use(protoValue)
+	}
+	if len(s.Constant) > 0 {
+		return protoValueType(s.Constant[0])
+	}
+	if len(s.Enum) > 0 {
+		r.module.Warn(s, "an enum of other values than strings cannot be represented in protocol buffers")
+		return protoValueType(s.Enum[0])
+	}
+
+	types := codegen.NonNull(s.Types)
+	if len(types) == 0 && markdown.AsSchema(markdown.AdditionalProperties(s)) != nil {
+		types = []string{"object"}
+	}
+	if len(types) > 1 {
+		r.module.Warn(s, "a nested union of types cannot be represented in protocol buffers, it is exported as a %s", protoValue)
+	}
+	if len(types) != 1 {
+		return r.use(protoValue)
+	}
+
+	switch types[0] {
+	case "string":
+		switch s.Format {
+		case "":
+		case "date-time":
+			return r.use(protoTimestamp)
+		default:
+			r.module.Warn(s, "format %s cannot be represented in protocol buffers", s.Format)
+		}
+		if s.ContentEncoding == "base64" {
+			return "bytes"
+		}
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "double"
+	case "boolean":
+		return "bool"
+	case "array":
+		items, tuple := items(s)
+		if tuple {
+			r.module.Warn(s, "tuples cannot be represented in protocol buffers, their items are exported as a %s", protoValue)
+			return "repeated " + r.use(protoValue)
+		}
+		if items == nil {
+			return "repeated " + r.use(protoValue)
+		}
+		return "repeated " + r.element(s, r.fieldType(name+"Item", items))
+	default:
+		additional := markdown.AsSchema(markdown.AdditionalProperties(s))
+		if additional == nil {
+			return r.use(protoValue)
+		}
+		return "map<string, " + r.element(s, r.fieldType(name+"Value", additional)) + ">"
+	}
+}
+
+// oneofBranches returns the branches of the oneof of a property: the branches of its oneOf or
+// anyOf composition, or a copy of the property for each of its types other than null, in which
+// case typed is set
+func oneofBranches(s *jsonschema.Schema) (branches []*jsonschema.Schema, typed bool) {
+	if branches = union(s); len(branches) > 0 {
+		return branches, false
+	}
+	types := codegen.NonNull(s.Types)
+	if len(types) < 2 || len(s.Constant) > 0 || len(s.Enum) > 0 {
+		return nil, false
+	}
+	for _, t := range types {
+		branch := *s
+		branch.Types = []string{t}
+		branches = append(branches, &branch)
+	}
+	return branches, true
+}
+
+// element returns the type of the items of an array, a map value or a oneof branch, which
+// cannot be repeated or a map themselves: nested arrays are lists and nested maps values
+func (r *protoRenderer) element(s *jsonschema.Schema, t string) string {
+	switch {
+	case strings.HasPrefix(t, "repeated "):
+		r.module.Warn(s, "nested arrays cannot be represented in protocol buffers, they are exported as a %s", protoListValue)
+		return r.use(protoListValue)
+	case strings.HasPrefix(t, "map<"):
+		r.module.Warn(s, "nested maps cannot be represented in protocol buffers, they are exported as a %s", protoValue)
+		return r.use(protoValue)
+	}
+	return t
+}
+
+// use returns a well-known type, importing the file declaring it
+func (r *protoRenderer) use(t string) string {
+	r.imports[protoImports[t]] = true
+	return t
+}
+
+// isScalar returns whether t is a scalar type, whose fields need the optional label to track
+// their presence
+func isScalar(t string) bool {
+	return t == strings.ToLower(t) && !strings.ContainsAny(t, " <.")
+}
+
+// protoDoc returns the comment of a description
+func protoDoc(description, indent string) string {
+	if len(description) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(description, "\n") {
+		b.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+	return b.String()
+}
+
+// protoValueType returns the protocol buffers type of a json value
+func protoValueType(v interface{}) string {
+	switch avroValueType(v) {
+	case "boolean":
+		return "bool"
+	case "long":
+		return "int64"
+	case "double":
+		return "double"
+	default:
+		return "string"
+	}
+}
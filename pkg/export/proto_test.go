@@ -0,0 +1,118 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/codegen"
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProto_Render(t *testing.T) {
+	files, warnings := generate(t, Proto{})
+
+	order := files["order.proto"]
+	assert.Contains(t, order, "syntax = \"proto3\";\n\npackage order;\n")
+	assert.Contains(t, order, "import \"customer.proto\";\nimport \"google/protobuf/timestamp.proto\";\n")
+	assert.Contains(t, order, "// An order\nmessage Order {\n")
+	assert.Contains(t, order, "  customer.Customer customer = 1;\n")
+	assert.Contains(t, order, "  // The id\n  string id = 2;\n")
+	assert.Contains(t, order, "  repeated Line lines = 3;\n")
+	assert.Contains(t, order, "  oneof payment {\n    Card payment_card = 4;\n    string payment_string = 5;\n  }\n")
+	assert.Contains(t, order, "  google.protobuf.Timestamp placed_at = 6 [json_name = \"placedAt\"];\n")
+	assert.Contains(t, order, "  optional int64 quantity = 7;\n")
+	assert.Contains(t, order, "  OrderStatus status = 8;\n")
+	assert.Contains(t, order, "  map<string, string> tags = 9;\n")
+	assert.Contains(t, order, "  OrderXMeta x_meta = 10 [json_name = \"x-meta\"];\n")
+	assert.Contains(t, order, "enum OrderStatus {\n  ORDER_STATUS_UNSPECIFIED = 0;\n  ORDER_STATUS_OPEN = 1;\n  ORDER_STATUS_CLOSED = 2;\n}")
+	assert.Contains(t, order, "message OrderXMeta {\n  optional bool a = 1;\n}")
+	assert.Contains(t, order, "message Line {\n  optional string sku = 1;\n}")
+	assert.Contains(t, order, "message Card {\n  optional string number = 1;\n}")
+
+	assert.Contains(t, files["customer.proto"], "package customer;\n\nmessage Customer {\n  optional string name = 1;\n}")
+	assert.Equal(t, []string{
+		"/export/order.schema.json#: the declared order of the properties of Order is unknown, fields customer, id, lines, payment, placedAt, quantity, status, tags, x-meta are numbered in alphabetical order",
+		"/export/order.schema.json#/properties/id: pattern cannot be represented in protocol buffers",
+		"/export/order.schema.json#/properties/quantity: minimum cannot be represented in protocol buffers",
+	}, warnings)
+}
+
+func TestProto_RenderDeclaredOrder(t *testing.T) {
+	documents := map[string]string{"order.schema.json": orderSchema, "customer.schema.json": customerSchema}
+	files, warnings := generateWith(t, documents, func(c *markdown.Converter) codegen.Language {
+		return Proto{Converter: c}
+	})
+
+	order := files["order.proto"]
+	assert.Contains(t, order, "  // The id\n  string id = 1;\n")
+	assert.Contains(t, order, "  OrderStatus status = 2;\n")
+	assert.Contains(t, order, "  optional int64 quantity = 3;\n")
+	assert.Contains(t, order, "  google.protobuf.Timestamp placed_at = 4 [json_name = \"placedAt\"];\n")
+	assert.Contains(t, order, "  customer.Customer customer = 5;\n")
+	assert.Contains(t, order, "  repeated Line lines = 6;\n")
+	assert.Contains(t, order, "  map<string, string> tags = 7;\n")
+	assert.Contains(t, order, "  OrderXMeta x_meta = 8 [json_name = \"x-meta\"];\n")
+	assert.Contains(t, order, "  oneof payment {\n    Card payment_card = 9;\n    string payment_string = 10;\n  }\n")
+	assert.Len(t, warnings, 2)
+}
+
+func TestProto_RenderFieldNumbers(t *testing.T) {
+	documents := map[string]string{"ticket.schema.json": `{
+  "title": "Ticket",
+  "type": "object",
+  "properties": {
+    "title": { "type": "string", "x-proto-field": 2 },
+    "id": { "type": "string", "x-proto-field": 1 },
+    "note": { "type": "string" },
+    "kind": { "oneOf": [{ "type": "string", "x-proto-field": 5 }, { "type": "integer" }] },
+    "bad": { "type": "string", "x-proto-field": 19500 },
+    "dup": { "type": "string", "x-proto-field": 2 }
+  }
+}`}
+	files, warnings := generateWith(t, documents, func(c *markdown.Converter) codegen.Language {
+		return Proto{Converter: c}
+	})
+
+	assert.Contains(t, files["ticket.proto"], `message Ticket {
+  optional string title = 2;
+  optional string id = 1;
+  optional string note = 3;
+  oneof kind {
+    string kind_string = 5;
+    int64 kind_int_64 = 4;
+  }
+  optional string bad = 6;
+  optional string dup = 7;
+}
+`)
+	assert.Equal(t, []string{
+		"/export/ticket.schema.json#/properties/bad: x-proto-field 19500 of bad is not a valid field number",
+		"/export/ticket.schema.json#/properties/dup: field number 2 of dup is already used, the field is numbered after its position",
+	}, warnings)
+}
+
+func TestProto_RenderTypeUnion(t *testing.T) {
+	documents := map[string]string{"product.schema.json": `{
+  "title": "Product",
+  "type": "object",
+  "properties": {
+    "productId": { "type": ["integer", "string", "null"] },
+    "codes": { "type": "array", "items": { "type": ["integer", "string"] } }
+  }
+}`}
+	files, warnings := generateWith(t, documents, func(c *markdown.Converter) codegen.Language {
+		return Proto{Converter: c}
+	})
+
+	assert.Contains(t, files["product.proto"], `message Product {
+  oneof product_id {
+    int64 product_id_int_64 = 1;
+    string product_id_string = 2;
+  }
+  repeated google.protobuf.Value codes = 3;
+}
+`)
+	assert.Equal(t, []string{
+		"/export/product.schema.json#/properties/codes/items: a nested union of types cannot be represented in protocol buffers, it is exported as a google.protobuf.Value",
+	}, warnings)
+}
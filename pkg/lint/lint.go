@@ -0,0 +1,219 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+type Severity int
+
+const (
+	Off Severity = iota
+	Info
+	Warning
+	Error
+)
+
+var severities = []string{"off", "info", "warning", "error"}
+
+func ParseSeverity(s string) (Severity, error) {
+	for i, name := range severities {
+		if strings.EqualFold(name, s) {
+			return Severity(i), nil
+		}
+	}
+	return Off, fmt.Errorf(`invalid severity "%s", expected one of %s`, s, strings.Join(severities, ", "))
+}
+
+func (s Severity) String() string {
+	if int(s) < len(severities) {
+		return severities[s]
+	}
+	return "unknown"
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *Severity) UnmarshalJSON(b []byte) (err error) {
+	var name string
+	if err = json.Unmarshal(b, &name); err != nil {
+		return err
+	}
+	*s, err = ParseSeverity(name)
+	return err
+}
+
+// Finding is a documentation-quality problem found in a schema
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Pointer  string   `json:"pointer"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Rule checks the schemas in a Context and reports its findings
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity
+	Check       func(ctx *Context, report Reporter)
+}
+
+// Reporter reports a finding for a schema
+type Reporter func(s *markdown.Schema, format string, args ...interface{})
+
+// Context holds the schemas being linted
+type Context struct {
+	// Schemas are the root schemas of the loaded files
+	Schemas []*markdown.Schema
+
+	// Document returns the raw json of a loaded file
+	Document func(path string) markdown.RawSchema
+
+	documents map[string]bool
+}
+
+// Local reports whether the schema belongs to one of the loaded files
+func (ctx *Context) Local(s *markdown.Schema) bool {
+	return ctx.documents[markdown.TrimAnchorPath(s.Location)]
+}
+
+// Walk calls fn for each schema of the loaded files, following references
+func (ctx *Context) Walk(fn func(s *markdown.Schema)) {
+	visited := map[string]bool{}
+	for _, root := range ctx.Schemas {
+		root.WalkSchema(true, func(s *markdown.Schema) error {
+			if visited[s.Location] || !ctx.Local(s) {
+				return nil
+			}
+			visited[s.Location] = true
+			fn(s)
+			return nil
+		})
+	}
+}
+
+type Linter struct {
+	rules    []Rule
+	severity map[string]Severity
+}
+
+// NewLinter returns a linter with the default rules, overriding the severity of the
+// rules named in overrides
+func NewLinter(overrides map[string]string) (*Linter, error) {
+	l := &Linter{rules: Rules(), severity: map[string]Severity{}}
+	for _, rule := range l.rules {
+		l.severity[rule.Name] = rule.Severity
+	}
+
+	for name, value := range overrides {
+		if _, ok := l.severity[name]; !ok {
+			return nil, fmt.Errorf(`unknown lint rule "%s"`, name)
+		}
+		severity, err := ParseSeverity(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid severity for rule %s", name)
+		}
+		l.severity[name] = severity
+	}
+	return l, nil
+}
+
+// Lint runs the enabled rules against the schemas and returns the findings sorted by
+// file, line and pointer
+func (l *Linter) Lint(schemas []*markdown.Schema, document func(path string) markdown.RawSchema) []Finding {
+	ctx := &Context{Schemas: schemas, Document: document, documents: map[string]bool{}}
+	for _, s := range schemas {
+		ctx.documents[markdown.TrimAnchorPath(s.Location)] = true
+	}
+
+	var findings []Finding
+	for _, rule := range l.rules {
+		severity := l.severity[rule.Name]
+		if severity == Off {
+			continue
+		}
+
+		rule.Check(ctx, func(s *markdown.Schema, format string, args ...interface{}) {
+			findings = append(findings, Finding{
+				Rule:     rule.Name,
+				Severity: severity,
+				File:     markdown.FileFromURL(markdown.TrimAnchorPath(s.Location)),
+				Pointer:  markdown.Pointer(s.Location),
+				Message:  fmt.Sprintf(format, args...),
+			})
+		})
+	}
+
+	contents := map[string][]byte{}
+	for i, f := range findings {
+		if _, ok := contents[f.File]; !ok {
+			contents[f.File], _ = afero.ReadFile(markdown.AppFS, f.File)
+		}
+		if len(contents[f.File]) > 0 {
+			findings[i].Line = markdown.PointerLine(contents[f.File], f.Pointer)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Pointer != b.Pointer {
+			return a.Pointer < b.Pointer
+		}
+		return a.Rule < b.Rule
+	})
+	return findings
+}
+
+// Exceeds reports whether any finding is at or above the severity
+func Exceeds(findings []Finding, severity Severity) bool {
+	for _, f := range findings {
+		if f.Severity >= severity {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes one line per finding
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, f.Line)
+		}
+		pointer := markdown.FirstNonEmpty(f.Pointer, "/")
+		if _, err := fmt.Fprintf(w, "%s %s [%s] %s: %s\n", location, pointer, f.Severity, f.Rule, f.Message); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d finding(s)\n", len(findings))
+	return err
+}
+
+// WriteJSON writes the findings as a json array
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
@@ -0,0 +1,101 @@
+package lint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const schema = `{
+  "title": "Order",
+  "description": "An order",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["orderId", "total"],
+  "properties": {
+    "orderId": { "type": "string", "description": "The id" },
+    "line_items": { "type": "array", "description": "The items" },
+    "status": { "enum": [], "description": "The status" },
+    "customer": { "$ref": "#/$defs/customer" }
+  },
+  "$defs": {
+    "customer": {
+      "title": "Customer",
+      "description": "A customer",
+      "type": "object",
+      "properties": { "name": { "type": "string", "description": "The name" } }
+    },
+    "unused": { "type": "string" }
+  }
+}`
+
+func lintSchema(t *testing.T, overrides map[string]string) []Finding {
+	markdown.AppFS = afero.NewMemMapFs()
+	assert.Nil(t, afero.WriteFile(markdown.AppFS, "/lint/order.schema.json", []byte(schema), os.ModePerm))
+
+	c := markdown.NewConverter(markdown.Config{Extension: "*.schema.json"})
+	schemas, err := c.Load("/lint")
+	assert.Nil(t, err)
+
+	linter, err := NewLinter(overrides)
+	assert.Nil(t, err)
+	return linter.Lint(schemas, c.Document)
+}
+
+func TestLinter_Lint(t *testing.T) {
+	findings := lintSchema(t, nil)
+
+	actual := map[string]string{}
+	for _, f := range findings {
+		actual[f.Rule+" "+f.Pointer] = f.Severity.String()
+	}
+
+	assert.Equal(t, map[string]string{
+		"required-undefined ":                                 "error",
+		"empty-enum /properties/status":                       "error",
+		"inconsistent-property-casing /properties/line_items": "warning",
+		"unreachable-definition /$defs/unused":                "warning",
+		"unused-defs /$defs/unused":                           "info",
+		"implicit-additional-properties /$defs/customer":      "info",
+	}, actual)
+}
+
+func TestLinter_Overrides(t *testing.T) {
+	findings := lintSchema(t, map[string]string{"empty-enum": "off", "unused-defs": "error"})
+	for _, f := range findings {
+		assert.NotEqual(t, "empty-enum", f.Rule)
+		if f.Rule == "unused-defs" {
+			assert.Equal(t, Error, f.Severity)
+		}
+	}
+
+	_, err := NewLinter(map[string]string{"unknown": "off"})
+	assert.NotNil(t, err)
+
+	_, err = NewLinter(map[string]string{"empty-enum": "fatal"})
+	assert.NotNil(t, err)
+}
+
+func TestExceeds(t *testing.T) {
+	findings := []Finding{{Severity: Warning}}
+	assert.True(t, Exceeds(findings, Info))
+	assert.True(t, Exceeds(findings, Warning))
+	assert.False(t, Exceeds(findings, Error))
+}
+
+func TestCasing(t *testing.T) {
+	testCases := map[string]string{
+		"productId":  "camelCase",
+		"ProductId":  "PascalCase",
+		"product_id": "snake_case",
+		"product-id": "kebab-case",
+		"product":    "",
+	}
+
+	for val, expected := range testCases {
+		assert.Equal(t, expected, Casing(val))
+	}
+}
@@ -0,0 +1,234 @@
+package lint
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/SPANDigital/presidium-json-schema/pkg/markdown"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// definitionKeywords are the keywords under which a schema declares its definitions
+var definitionKeywords = []string{"definitions", "$defs"}
+
+// Rules returns the available rules with their default severity
+func Rules() []Rule {
+	return []Rule{
+		{"missing-title", "schemas and definitions should have a title", Warning, missingTitle},
+		{"missing-description", "schemas, definitions and properties should have a description", Warning, missingDescription},
+		{"required-undefined", "properties listed in required should be defined", Error, requiredUndefined},
+		{"unreachable-definition", "definitions should be referenced by at least one schema", Warning, unreachableDefinition},
+		{"unused-defs", "definitions should be referenced within the schema that declares them", Info, unusedDefs},
+		{"empty-enum", "enums should list at least one value", Error, emptyEnum},
+		{"implicit-additional-properties", "objects should declare additionalProperties explicitly", Info, implicitAdditionalProperties},
+		{"inconsistent-property-casing", "property names should follow the casing used by the rest of the file", Warning, inconsistentCasing},
+	}
+}
+
+// pages returns the schemas which are rendered as their own page: the root schemas and
+// the definitions they reference
+func pages(ctx *Context) []*markdown.Schema {
+	var result []*markdown.Schema
+	unique := map[string]bool{}
+	add := func(s *markdown.Schema) {
+		if !unique[s.Location] && ctx.Local(s) {
+			unique[s.Location] = true
+			result = append(result, s)
+		}
+	}
+
+	for _, root := range ctx.Schemas {
+		add(root)
+		for _, def := range root.Definitions() {
+			add(def)
+		}
+	}
+	return result
+}
+
+func missingTitle(ctx *Context, report Reporter) {
+	for _, s := range pages(ctx) {
+		if len(s.Title) == 0 {
+			report(s, "schema has no title")
+		}
+	}
+}
+
+func missingDescription(ctx *Context, report Reporter) {
+	for _, s := range pages(ctx) {
+		if len(s.Description) == 0 {
+			report(s, "schema has no description")
+		}
+	}
+
+	ctx.Walk(func(s *markdown.Schema) {
+		for name, prop := range s.Properties {
+			if len(prop.Description) > 0 || (prop.Ref != nil && len(prop.Ref.Description) > 0) {
+				continue
+			}
+			report(markdown.ToSchema(prop, s.Path), "property %s has no description", name)
+		}
+	})
+}
+
+func requiredUndefined(ctx *Context, report Reporter) {
+	ctx.Walk(func(s *markdown.Schema) {
+		if len(s.Properties) == 0 {
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := s.Properties[name]; !ok {
+				report(s, "required property %s is not defined", name)
+			}
+		}
+	})
+}
+
+func unreachableDefinition(ctx *Context, report Reporter) {
+	reached := map[string]bool{}
+	for _, root := range ctx.Schemas {
+		root.WalkSchema(true, func(s *markdown.Schema) error {
+			reached[s.Location] = true
+			return nil
+		})
+	}
+
+	for _, root := range ctx.Schemas {
+		for _, def := range definitions(ctx, root) {
+			if !reached[def.Location] {
+				report(def, "definition is not referenced by any schema")
+			}
+		}
+	}
+}
+
+func unusedDefs(ctx *Context, report Reporter) {
+	for _, root := range ctx.Schemas {
+		document := markdown.TrimAnchorPath(root.Location)
+		used := map[string]bool{}
+		root.WalkSchema(true, func(s *markdown.Schema) error {
+			if markdown.TrimAnchorPath(s.Location) == document && s.Location != root.Location {
+				used[s.Location] = true
+			}
+			return nil
+		})
+
+		for _, def := range definitions(ctx, root) {
+			if !used[def.Location] {
+				report(def, "definition is not referenced within %s", markdown.FileFromURL(document))
+			}
+		}
+	}
+}
+
+// definitions returns the definitions declared by the raw document of a root schema,
+// as schemas holding only their location
+func definitions(ctx *Context, root *markdown.Schema) []*markdown.Schema {
+	raw := ctx.Document(root.Path)
+	document := markdown.TrimAnchorPath(root.Location)
+
+	var result []*markdown.Schema
+	for _, keyword := range definitionKeywords {
+		defs, ok := raw[keyword].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var names []string
+		for name := range defs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			location := document + "#/" + keyword + "/" + escape(name)
+			result = append(result, markdown.ToSchema(&jsonschema.Schema{Location: location}, root.Path))
+		}
+	}
+	return result
+}
+
+func emptyEnum(ctx *Context, report Reporter) {
+	ctx.Walk(func(s *markdown.Schema) {
+		if s.Enum != nil && len(s.Enum) == 0 {
+			report(s, "enum has no values")
+		}
+	})
+}
+
+func implicitAdditionalProperties(ctx *Context, report Reporter) {
+	ctx.Walk(func(s *markdown.Schema) {
+		if len(s.Properties) > 0 && markdown.AdditionalProperties(s.Schema) == nil && s.UnevaluatedProperties == nil {
+			report(s, "additionalProperties is not declared")
+		}
+	})
+}
+
+func inconsistentCasing(ctx *Context, report Reporter) {
+	type property struct {
+		name   string
+		style  string
+		schema *markdown.Schema
+	}
+
+	files := map[string][]property{}
+	ctx.Walk(func(s *markdown.Schema) {
+		document := markdown.TrimAnchorPath(s.Location)
+		for name, prop := range s.Properties {
+			if style := Casing(name); len(style) > 0 {
+				files[document] = append(files[document], property{name, style, markdown.ToSchema(prop, s.Path)})
+			}
+		}
+	})
+
+	for _, props := range files {
+		count := map[string]int{}
+		for _, p := range props {
+			count[p.style]++
+		}
+
+		var dominant string
+		for style, n := range count {
+			if n > count[dominant] || (n == count[dominant] && style < dominant) {
+				dominant = style
+			}
+		}
+
+		for _, p := range props {
+			if p.style != dominant {
+				report(p.schema, "property %s is %s while most properties in this file are %s", p.name, p.style, dominant)
+			}
+		}
+	}
+}
+
+// Casing returns the casing style of a property name, or an empty string when the name
+// is a single lowercase word which fits any style
+func Casing(name string) string {
+	if len(name) == 0 {
+		return ""
+	}
+
+	hasUpper := strings.IndexFunc(name, unicode.IsUpper) >= 0
+	switch {
+	case strings.Contains(name, "_"):
+		return "snake_case"
+	case strings.Contains(name, "-"):
+		return "kebab-case"
+	case unicode.IsUpper([]rune(name)[0]):
+		return "PascalCase"
+	case hasUpper:
+		return "camelCase"
+	default:
+		return ""
+	}
+}
+
+// escape escapes a json pointer token the way the compiler does in schema locations
+func escape(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return url.PathEscape(token)
+}
@@ -0,0 +1,292 @@
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// asyncAPIDraft is the draft the schema objects of AsyncAPI documents are a superset of
+const asyncAPIDraft = "http://json-schema.org/draft-07/schema#"
+
+// IsAsyncAPI reports whether the document is an AsyncAPI document rather than a json schema
+func IsAsyncAPI(doc RawSchema) bool {
+	_, ok := doc["asyncapi"].(string)
+	return ok
+}
+
+// AsyncAPISchema translates an AsyncAPI 2.x or 3.x document into a json schema document
+// titled after the api, and returns the pointers of its channels, messages and component
+// schemas, which are rendered as definition pages.
+//
+// Each message becomes an object schema whose payload and headers properties hold the
+// schemas of the message, messages defined inline being moved to components.messages. Each
+// channel becomes a schema, under channels, which is one of the messages it carries. The
+// components are kept at the same location, so #/components/schemas/X and
+// #/components/messages/X refs resolve.
+func AsyncAPISchema(doc RawSchema) (RawSchema, []string, error) {
+	version, _ := doc["asyncapi"].(string)
+	if !strings.HasPrefix(version, "2.") && !strings.HasPrefix(version, "3.") {
+		return nil, nil, fmt.Errorf(`unsupported AsyncAPI version "%s", expected 2.x or 3.x`, version)
+	}
+
+	root := RawSchema{"$schema": asyncAPIDraft}
+	if info, ok := doc["info"].(map[string]interface{}); ok {
+		for _, key := range []string{"title", "description"} {
+			if value, ok := info[key]; ok {
+				root[key] = value
+			}
+		}
+	}
+
+	components, _ := doc["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	messages, _ := components["messages"].(map[string]interface{})
+	if schemas == nil {
+		schemas = map[string]interface{}{}
+	}
+	if messages == nil {
+		messages = map[string]interface{}{}
+	}
+
+	a := &asyncAPI{doc: doc, messages: messages, v3: strings.HasPrefix(version, "3.")}
+	channels := a.channels()
+	root["channels"] = channels
+	root["components"] = map[string]interface{}{"schemas": schemas, "messages": messages}
+
+	for name, message := range messages {
+		if m, ok := message.(map[string]interface{}); ok {
+			messages[name] = messageSchema(name, m)
+		}
+	}
+
+	var pointers []string
+	for _, name := range sortedKeys(channels) {
+		pointers = append(pointers, "#/channels/"+escapePointer(name))
+	}
+	for _, name := range sortedKeys(messages) {
+		pointers = append(pointers, "#/components/messages/"+escapePointer(name))
+	}
+	pointers = append(pointers, translateComponents(schemas, "#/components/schemas", translateAsyncAPI)...)
+	for _, m := range messages {
+		if m, ok := m.(map[string]interface{}); ok {
+			RawSchema(m).Walk(func(s RawSchema, _ string) { translateAsyncAPI(s) })
+		}
+	}
+	return root, pointers, nil
+}
+
+// asyncAPI translates the channels of an AsyncAPI document
+type asyncAPI struct {
+	doc      RawSchema
+	messages map[string]interface{}
+	v3       bool
+}
+
+// channels returns the schema of each channel, which is one of the messages it carries
+func (a *asyncAPI) channels() map[string]interface{} {
+	channels := map[string]interface{}{}
+	raw, _ := a.doc["channels"].(map[string]interface{})
+	for _, name := range sortedKeys(raw) {
+		channel, ok := a.resolve(raw[name]).(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		schema := map[string]interface{}{"title": name}
+		if a.v3 {
+			if address, ok := channel["address"].(string); ok && len(address) > 0 {
+				schema["title"] = address
+			}
+		}
+		if title, ok := channel["title"].(string); ok {
+			schema["title"] = title
+		}
+		if description, ok := channel["description"]; ok {
+			schema["description"] = description
+		}
+
+		var refs []interface{}
+		for _, ref := range a.channelMessages(name, channel) {
+			refs = append(refs, map[string]interface{}{"$ref": ref})
+		}
+		if len(refs) > 0 {
+			schema["oneOf"] = refs
+		}
+		channels[name] = schema
+	}
+	return channels
+}
+
+// channelMessages returns the refs of the messages a channel carries, the messages defined
+// inline are moved to the components
+func (a *asyncAPI) channelMessages(name string, channel map[string]interface{}) []string {
+	var refs []string
+	add := func(key string, message interface{}) {
+		if m, ok := message.(map[string]interface{}); ok {
+			if list, ok := m["oneOf"].([]interface{}); ok {
+				for i, item := range list {
+					refs = appendUnique(refs, a.messageRef(fmt.Sprintf("%s-%d", key, i), item))
+				}
+				return
+			}
+		}
+		if ref := a.messageRef(key, message); len(ref) > 0 {
+			refs = appendUnique(refs, ref)
+		}
+	}
+
+	if a.v3 {
+		messages, _ := channel["messages"].(map[string]interface{})
+		for _, id := range sortedKeys(messages) {
+			add(id, messages[id])
+		}
+		return refs
+	}
+
+	for _, operation := range []string{"publish", "subscribe"} {
+		if op, ok := channel[operation].(map[string]interface{}); ok {
+			add(Slugify(name+" "+operation), op["message"])
+		}
+	}
+	return refs
+}
+
+// messageRef returns the ref of a message, moving the message to the components when it is
+// defined inline
+func (a *asyncAPI) messageRef(key string, message interface{}) string {
+	m, ok := message.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if ref, ok := m["$ref"].(string); ok {
+		return ref
+	}
+
+	for _, k := range []string{"messageId", "name"} {
+		if id, ok := m[k].(string); ok && len(id) > 0 {
+			key = id
+			break
+		}
+	}
+	name := key
+	for i := 2; a.messages[name] != nil; i++ {
+		name = fmt.Sprintf("%s-%d", key, i)
+	}
+	a.messages[name] = m
+	return "#/components/messages/" + escapePointer(name)
+}
+
+// resolve returns the value a local $ref points to, or the value itself
+func (a *asyncAPI) resolve(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	ref, ok := m["$ref"].(string)
+	if !ok || !strings.HasPrefix(ref, "#/") {
+		return v
+	}
+
+	var value interface{} = map[string]interface{}(a.doc)
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[unescapePointer(token)]
+	}
+	return value
+}
+
+// messageSchema returns the object schema of a message, whose payload and headers
+// properties hold the schemas of the message
+func messageSchema(name string, message map[string]interface{}) map[string]interface{} {
+	if _, ok := message["$ref"]; ok {
+		return message
+	}
+
+	schema := map[string]interface{}{"type": "object"}
+	for _, key := range []string{"title", "name"} {
+		if title, ok := message[key].(string); ok && len(title) > 0 {
+			schema["title"] = title
+			break
+		}
+	}
+	if _, ok := schema["title"]; !ok {
+		schema["title"] = name
+	}
+	for _, key := range []string{"description", "summary"} {
+		if description, ok := message[key].(string); ok && len(description) > 0 {
+			schema["description"] = description
+			break
+		}
+	}
+
+	properties := map[string]interface{}{}
+	for _, key := range []string{"headers", "payload"} {
+		if s, ok := messageSchemaObject(message[key], message["schemaFormat"]); ok {
+			properties[key] = s
+		}
+	}
+	if len(properties) > 0 {
+		schema["properties"] = properties
+	}
+	if _, ok := properties["payload"]; ok {
+		schema["required"] = []interface{}{"payload"}
+	}
+	return schema
+}
+
+// messageSchemaObject returns the json schema of the payload or headers of a message, a
+// schema in another format, e.g. avro, is only described
+func messageSchemaObject(v interface{}, format interface{}) (interface{}, bool) {
+	s, ok := v.(map[string]interface{})
+	if !ok {
+		if b, ok := v.(bool); ok {
+			return b, true
+		}
+		return nil, false
+	}
+
+	// an AsyncAPI 3 multi format schema
+	if schema, ok := s["schema"]; ok {
+		if f, ok := s["schemaFormat"]; ok {
+			s, format = nil, f
+			if m, ok := schema.(map[string]interface{}); ok {
+				s = m
+			}
+		}
+	}
+
+	if f, ok := format.(string); ok && !isJSONSchemaFormat(f) {
+		return map[string]interface{}{"description": fmt.Sprintf("A schema in the %s format", f)}, true
+	}
+	if s == nil {
+		return nil, false
+	}
+	return s, true
+}
+
+func isJSONSchemaFormat(format string) bool {
+	return strings.Contains(format, "schema+json") || strings.Contains(format, "schema+yaml") ||
+		strings.HasPrefix(format, "application/vnd.aai.asyncapi")
+}
+
+// translateAsyncAPI translates the AsyncAPI keywords of a schema object to json schema
+func translateAsyncAPI(s RawSchema) {
+	// the discriminator of AsyncAPI is the name of the property
+	if name, ok := s["discriminator"].(string); ok {
+		s["discriminator"] = map[string]interface{}{"propertyName": name}
+	}
+	translateOpenAPI(s, false)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
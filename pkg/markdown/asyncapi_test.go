@@ -0,0 +1,137 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const ordersAsyncAPI = `asyncapi: 2.6.0
+info:
+  title: Orders
+  description: Order events
+  version: 1.0.0
+channels:
+  orders/created:
+    description: Orders which were placed
+    subscribe:
+      message:
+        $ref: '#/components/messages/OrderCreated'
+  orders/cancelled:
+    publish:
+      message:
+        name: OrderCancelled
+        summary: An order was cancelled
+        payload:
+          type: object
+          properties:
+            id:
+              type: string
+components:
+  messages:
+    OrderCreated:
+      summary: An order was placed
+      headers:
+        type: object
+        properties:
+          correlationId:
+            type: string
+      payload:
+        $ref: '#/components/schemas/Order'
+  schemas:
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+        total:
+          type: number
+`
+
+const accountsAsyncAPI = `asyncapi: 3.0.0
+info:
+  title: Accounts
+  version: 1.0.0
+channels:
+  userSignedUp:
+    $ref: '#/components/channels/userSignedUp'
+components:
+  channels:
+    userSignedUp:
+      address: user/signedup
+      messages:
+        UserSignedUp:
+          $ref: '#/components/messages/UserSignedUp'
+        UserSignedUpAvro:
+          payload:
+            schemaFormat: application/vnd.apache.avro;version=1.9.0
+            schema:
+              type: record
+  messages:
+    UserSignedUp:
+      title: User signed up
+      payload:
+        type: object
+        discriminator: kind
+        properties:
+          kind:
+            type: string
+`
+
+func TestAsyncAPISchema(t *testing.T) {
+	var v interface{}
+	assert.Nil(t, yaml.Unmarshal([]byte(accountsAsyncAPI), &v))
+	doc := RawSchema(jsonValue(v).(map[string]interface{}))
+
+	schema, pointers, err := AsyncAPISchema(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"#/channels/userSignedUp",
+		"#/components/messages/UserSignedUp",
+		"#/components/messages/UserSignedUpAvro",
+	}, pointers)
+
+	channel := schema["channels"].(map[string]interface{})["userSignedUp"].(map[string]interface{})
+	assert.Equal(t, "user/signedup", channel["title"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"$ref": "#/components/messages/UserSignedUp"},
+		map[string]interface{}{"$ref": "#/components/messages/UserSignedUpAvro"},
+	}, channel["oneOf"])
+
+	messages := schema["components"].(map[string]interface{})["messages"].(map[string]interface{})
+	signedUp := messages["UserSignedUp"].(map[string]interface{})
+	assert.Equal(t, "User signed up", signedUp["title"])
+	payload := signedUp["properties"].(map[string]interface{})["payload"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"propertyName": "kind"}, payload["discriminator"])
+	assert.Equal(t, []interface{}{"kind"}, payload["required"])
+
+	avro := messages["UserSignedUpAvro"].(map[string]interface{})["properties"].(map[string]interface{})["payload"]
+	assert.Equal(t, map[string]interface{}{"description": "A schema in the application/vnd.apache.avro;version=1.9.0 format"}, avro)
+
+	_, _, err = AsyncAPISchema(RawSchema{"asyncapi": "1.2.0"})
+	assert.NotNil(t, err)
+}
+
+func TestConverter_ConvertAsyncAPI(t *testing.T) {
+	writeSchema(t, "/asyncapi/orders.yaml", ordersAsyncAPI)
+	cfg := config
+	cfg.Destination = "/asyncapi-output"
+	assert.Nil(t, NewConverter(cfg).Convert("/asyncapi/orders.yaml"))
+
+	files := readTree(t, cfg.Destination)
+	index := files["orders/_index.md"]
+	assert.Contains(t, index, "**Channels:**\n\n- [orders/cancelled]({{%baseurl%}}/reference/orders/channels/#orders-cancelled)")
+	assert.Contains(t, index, "**Messages:**\n\n- [OrderCancelled]({{%baseurl%}}/reference/orders/messages/#order-cancelled): An order was cancelled")
+	assert.Contains(t, index, "**Schemas:**\n\n- [Order]({{%baseurl%}}/reference/orders/schemas/#order)")
+
+	assert.Contains(t, files["orders/channels/orders-created.md"], "| ItemType[0] | [OrderCreated]({{%baseurl%}}/reference/orders/messages/#order-created) |")
+
+	created := files["orders/messages/order-created.md"]
+	assert.Contains(t, created, "| headers.correlationId | String |")
+	assert.Contains(t, created, "| payload | [Order]({{%baseurl%}}/reference/orders/schemas/#order) |")
+	assert.Contains(t, created, "- [orders/created]({{%baseurl%}}/reference/orders/channels/#orders-created) `oneOf[0]`")
+
+	assert.Contains(t, files["orders/messages/order-cancelled.md"], "| payload.id | String |")
+	assert.Contains(t, files["orders/schemas/order.md"], "- [OrderCreated]({{%baseurl%}}/reference/orders/messages/#order-created) `payload`")
+}
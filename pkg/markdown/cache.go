@@ -0,0 +1,182 @@
+package markdown
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/SPANDigital/presidium-json-schema/templates"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// CacheFile is the name of the cache file kept in the destination directory
+const CacheFile = ".presidium-json-schema.cache.json"
+
+// Cache records what each generated page was rendered from, so unchanged pages can be
+// skipped on the next run.
+type Cache struct {
+	// Key is a hash of the configuration and templates the pages were rendered with
+	Key string `json:"key"`
+
+	// Schemas maps each schema document to the hash of its content
+	Schemas map[string]string `json:"schemas"`
+
+	// Pages maps each generated file to its content hash and the schema documents,
+	// including transitive $ref dependencies, it was rendered from
+	Pages map[string]CachedPage `json:"pages"`
+
+	// reused are the pages carried over from the previous cache rather than rendered
+	reused map[string]bool
+
+	mu sync.Mutex
+}
+
+type CachedPage struct {
+	Hash    string   `json:"hash"`
+	Schemas []string `json:"schemas"`
+}
+
+func NewCache(key string) *Cache {
+	return &Cache{
+		Key:     key,
+		Schemas: map[string]string{},
+		Pages:   map[string]CachedPage{},
+		reused:  map[string]bool{},
+	}
+}
+
+// LoadCache reads the cache from path, an empty cache is returned if it does not exist
+func LoadCache(path string) (*Cache, error) {
+	b, err := afero.ReadFile(AppFS, path)
+	if os.IsNotExist(err) {
+		return NewCache(""), nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cache: %s", path)
+	}
+
+	cache := NewCache("")
+	if err = json.Unmarshal(b, cache); err != nil {
+		log.Warnf("ignoring invalid cache %s: %v", path, err)
+		return NewCache(""), nil
+	}
+	return cache, nil
+}
+
+// Save writes the cache to path
+func (c *Cache) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cache")
+	}
+	if err = AppFS.MkdirAll(filepath.Dir(path), fs.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to create cache directory: %s", path)
+	}
+	return afero.WriteFile(AppFS, path, b, os.ModePerm)
+}
+
+// Reuse carries the page at path over from the previous cache when it was rendered with
+// the same key from the same unchanged schemas, and has not been modified since. A page
+// whose dependencies differ, e.g. because a schema started referencing it, is rendered again.
+func (c *Cache) Reuse(previous *Cache, path string, schemas []string) bool {
+	if previous == nil || previous.Key != c.Key {
+		return false
+	}
+
+	page, ok := previous.Pages[path]
+	if !ok || strings.Join(page.Schemas, "\n") != strings.Join(schemas, "\n") {
+		return false
+	}
+
+	c.mu.Lock()
+	for _, schema := range page.Schemas {
+		if previous.Schemas[schema] != c.Schemas[schema] {
+			c.mu.Unlock()
+			return false
+		}
+	}
+	c.mu.Unlock()
+
+	b, err := afero.ReadFile(AppFS, path)
+	if err != nil || Hash(string(b)) != page.Hash {
+		return false
+	}
+
+	c.Record(path, page.Hash, page.Schemas)
+	c.mu.Lock()
+	c.reused[path] = true
+	c.mu.Unlock()
+	return true
+}
+
+// Record stores the content hash and schema dependencies of the page at path
+func (c *Cache) Record(path, hash string, schemas []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Pages[path] = CachedPage{Hash: hash, Schemas: schemas}
+}
+
+// AddSchema stores the content hash of a schema document
+func (c *Cache) AddSchema(document, hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Schemas[document] = hash
+}
+
+// CacheKey hashes the configuration and the templates that affect the rendered output
+func CacheKey(config Config) (string, error) {
+	config.Jobs = 0
+	config.Clean = false
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	content := string(b)
+	err = fs.WalkDir(templates.Files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		t, err := fs.ReadFile(templates.Files, path)
+		content += path + string(t)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	overrides, err := TemplateOverrides(config.Templates)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range overrides {
+		t, err := afero.ReadFile(AppFS, path)
+		if err != nil {
+			return "", err
+		}
+		content += path + string(t)
+	}
+	return Hash(content), nil
+}
+
+// WriteIfChanged writes content to path unless the file already has that content
+func WriteIfChanged(path string, content []byte) (bool, error) {
+	if existing, err := afero.ReadFile(AppFS, path); err == nil && string(existing) == string(content) {
+		return false, nil
+	}
+	if err := AppFS.MkdirAll(filepath.Dir(path), fs.ModePerm); err != nil {
+		return false, errors.Wrapf(err, "failed to create md directory: %s", path)
+	}
+	if err := afero.WriteFile(AppFS, path, content, os.ModePerm); err != nil {
+		return false, errors.Wrapf(err, "failed to create md file: %s", path)
+	}
+	return true, nil
+}
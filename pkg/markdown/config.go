@@ -1,6 +1,19 @@
 package markdown
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
+
+// The strategies used to link to the page of a schema
+const (
+	// LinksBaseURL links to {{%baseurl%}}/<reference>/<path>/#<anchor>
+	LinksBaseURL = "baseurl"
+	// LinksRelref links using hugo relref shortcodes computed from the output paths
+	LinksRelref = "relref"
+	// LinksRelative links using plain markdown links relative to the output paths
+	LinksRelative = "relative"
+)
 
 type Config struct {
 	Destination     string
@@ -10,6 +23,21 @@ type Config struct {
 	OrderedFilePath bool
 	Local           bool
 	Clean           bool
+	Jobs            int
+	Cache           bool
+	Prune           bool
+	Samples         bool
+	SinglePage      bool
+	Links           string
+	FrontMatter     FrontMatter
+	Templates       string
+	InlineDepth     int
+	Collapse        bool
+	FlattenAllOf    bool
+	Draft           string
+	Formats         map[string]string
+	Keywords        []string
+	Regex           string
 }
 
 func (c Config) ReferenceUrl() string {
@@ -21,3 +49,47 @@ func (c Config) ReferenceUrl() string {
 	}
 	return c.Destination[offset:]
 }
+
+// LinkStrategy returns the strategy used to link to the page of a schema, which defaults
+// to LinksBaseURL
+func (c Config) LinkStrategy() (string, error) {
+	switch c.Links {
+	case "":
+		return LinksBaseURL, nil
+	case LinksBaseURL, LinksRelref, LinksRelative:
+		return c.Links, nil
+	default:
+		return "", fmt.Errorf(`invalid link strategy "%s", expected one of %s, %s or %s`, c.Links, LinksBaseURL, LinksRelref, LinksRelative)
+	}
+}
+
+// SchemaDraft returns the draft of the schemas lacking $schema, which defaults to
+// DefaultDraft. Drafts are named 4, 6, 7, 2019-09 or 2020-12, optionally prefixed with draft-.
+func (c Config) SchemaDraft() (Draft, error) {
+	if len(c.Draft) == 0 {
+		return DefaultDraft, nil
+	}
+
+	name := strings.TrimLeft(strings.TrimPrefix(c.Draft, "draft-"), "0")
+	var names []string
+	for _, d := range Drafts {
+		if d.Name == name {
+			return d, nil
+		}
+		names = append(names, d.Name)
+	}
+	return Draft{}, fmt.Errorf(`invalid draft "%s", expected one of %s`, c.Draft, strings.Join(names, ", "))
+}
+
+// RegexEngine returns the engine compiling the patterns the regexp package rejects, which
+// defaults to ECMAScriptRegexEngine
+func (c Config) RegexEngine() (RegexEngine, error) {
+	switch c.Regex {
+	case "", RegexECMAScript:
+		return ECMAScriptRegexEngine, nil
+	case RegexGo:
+		return GoRegexEngine, nil
+	default:
+		return nil, fmt.Errorf(`invalid regex engine "%s", expected %s or %s`, c.Regex, RegexECMAScript, RegexGo)
+	}
+}
@@ -19,3 +19,40 @@ func TestReferenceURL(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	}
 }
+
+func TestLinkStrategy(t *testing.T) {
+	testCases := map[string]string{
+		"":         LinksBaseURL,
+		"baseurl":  LinksBaseURL,
+		"relref":   LinksRelref,
+		"relative": LinksRelative,
+	}
+
+	for val, expected := range testCases {
+		actual, err := Config{Links: val}.LinkStrategy()
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	_, err := Config{Links: "absolute"}.LinkStrategy()
+	assert.NotNil(t, err)
+}
+
+func TestSchemaDraft(t *testing.T) {
+	testCases := map[string]string{
+		"":         "2020-12",
+		"4":        "4",
+		"draft-07": "7",
+		"2019-09":  "2019-09",
+		"2020-12":  "2020-12",
+	}
+
+	for val, expected := range testCases {
+		actual, err := Config{Draft: val}.SchemaDraft()
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual.Name)
+	}
+
+	_, err := Config{Draft: "3"}.SchemaDraft()
+	assert.NotNil(t, err)
+}
@@ -4,10 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/SPANDigital/presidium-json-schema/templates"
@@ -17,6 +23,7 @@ import (
 	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
 )
 
 type SchemaConverter interface {
@@ -30,6 +37,51 @@ type Converter struct {
 	converted map[string]bool
 	patterns  map[string]string
 	order     map[string]*orderedmap.OrderedMap
+	indexes   map[string]bool
+	sources   map[string]string
+	documents map[string]string
+	raw       map[string]RawSchema
+	cache     *Cache
+	previous  *Cache
+	manifest  *Manifest
+	generated *Manifest
+	refs      References
+	keywords  []string
+	engine    RegexEngine
+	fallbacks map[string]bool
+
+	// components are the pointers of the component schemas of the OpenAPI and AsyncAPI
+	// documents, which are rendered as definition pages, and compiled are their schemas
+	components map[string][]string
+	compiled   map[string][]*Schema
+
+	// pages are the paths of the planned pages by the location of their schema, and anchors
+	// the ids of the sections of single pages, which are only written while planning, so the
+	// templates may read them without locking
+	pages   map[string]string
+	anchors map[string]string
+
+	// resolved are the content hashes of the documents the compiler loaded itself, which the
+	// loaded schemas reference without them being loaded, by their url
+	resolved map[string]string
+
+	// formats are the formats registered with the converter, asserted by the formats
+	// extension, which are only written before loading
+	formats map[string]func(v interface{}) bool
+
+	// err is the first problem NewConverter found in the configuration, returned by Load
+	err error
+
+	// mu guards converted, patterns, order, indexes, sources, resolved, raw, fallbacks and
+	// components. patterns and order are only written while loading, so templates may read
+	// them without locking.
+	mu sync.RWMutex
+}
+
+// page is a single markdown file to be rendered from a schema
+type page struct {
+	name   string
+	schema *Schema
 }
 
 type middlewareFunc func(prop interface{}) interface{}
@@ -37,24 +89,73 @@ type middlewareFunc func(prop interface{}) interface{}
 func NewConverter(config Config) *Converter {
 	compiler := jsonschema.NewCompiler()
 	compiler.ExtractAnnotations = true
+	draft, err := config.SchemaDraft()
+	if err == nil {
+		compiler.Draft = draft.draft
+	}
 
-	return &Converter{
-		config:    config,
-		compiler:  compiler,
-		converted: map[string]bool{},
-		patterns:  map[string]string{},
-		order:     map[string]*orderedmap.OrderedMap{},
+	c := &Converter{
+		config:     config,
+		compiler:   compiler,
+		converted:  map[string]bool{},
+		patterns:   map[string]string{},
+		order:      map[string]*orderedmap.OrderedMap{},
+		indexes:    map[string]bool{},
+		sources:    map[string]string{},
+		documents:  map[string]string{},
+		resolved:   map[string]string{},
+		raw:        map[string]RawSchema{},
+		cache:      NewCache(""),
+		manifest:   NewManifest(),
+		generated:  NewManifest(),
+		refs:       References{},
+		engine:     ECMAScriptRegexEngine,
+		fallbacks:  map[string]bool{},
+		components: map[string][]string{},
+		compiled:   map[string][]*Schema{},
+		pages:      map[string]string{},
+		anchors:    map[string]string{},
+		formats:    map[string]func(v interface{}) bool{},
+		err:        err,
+	}
+	engine, err := config.RegexEngine()
+	if err == nil {
+		c.engine = engine
 	}
+	c.err = firstError(c.err, err)
+	compiler.LoadURL = c.loadURL
+	compiler.RegisterExtension("patterns", nil, patternExtension{c})
+	compiler.RegisterExtension("formats", nil, formatExtension{c})
+
+	formats, err := config.CompileFormats()
+	c.err = firstError(c.err, err)
+	for name, re := range formats {
+		c.err = firstError(c.err, c.RegisterFormat(name, RegexpFormat(re)))
+	}
+	return c
 }
 
-func (c Converter) Clean() error {
+// Clean removes the files the previous run generated, as listed by the manifest in the
+// destination, along with the manifest and the cache. The other files of the destination
+// are left untouched.
+func (c *Converter) Clean() error {
 	if !PathExist(c.config.Destination) {
 		return nil
 	}
-	if err := AppFS.RemoveAll(c.config.Destination); err != nil {
+	previous, err := LoadManifest(filepath.Join(c.config.Destination, ManifestFile))
+	if err != nil {
+		return err
+	}
+	if err = NewManifest().Prune(previous, c.config.Destination); err != nil {
 		return err
 	}
 
+	for _, name := range []string{ManifestFile, CacheFile} {
+		path := filepath.Join(c.config.Destination, name)
+		if err = AppFS.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove %s", path)
+		}
+	}
 	return nil
 }
 
@@ -66,41 +167,236 @@ func (c *Converter) Convert(path string) error {
 		}
 	}
 
+	if _, err := c.config.LinkStrategy(); err != nil {
+		return err
+	}
+
+	if err := c.config.FrontMatter.Validate(); err != nil {
+		return err
+	}
+
+	if c.err != nil {
+		return c.err
+	}
+
 	if err := c.parseTemplates(); err != nil {
 		return err
 	}
 
-	paths, err := FindFiles(path, c.config.Extension, c.config.Recursive)
+	if err := c.loadCache(); err != nil {
+		return err
+	}
+
+	if err := c.loadManifest(); err != nil {
+		return err
+	}
+
+	var problems []Problem
+	schemas, err := c.Load(path)
 	if err != nil {
+		var convertErr *ConvertError
+		if !errors.As(err, &convertErr) {
+			return err
+		}
+		problems = append(problems, convertErr.Problems...)
+	}
+
+	for path, document := range c.documents {
+		c.cache.AddSchema(document, c.sources[path])
+	}
+	for document, hash := range c.resolved {
+		c.cache.AddSchema(document, hash)
+	}
+
+	pageSchemas := schemas
+	for _, schema := range schemas {
+		pageSchemas = append(pageSchemas, c.compiled[schema.Path]...)
+	}
+	c.refs = NewReferences(pageSchemas)
+
+	pages, err := c.planPages(schemas)
+	if err != nil {
+		return err
+	}
+
+	if err := c.renderPages(pages); err != nil {
+		problems = append(problems, err.(*ConvertError).Problems...)
+	}
+
+	if err := c.renderIndexes(pages); err != nil {
 		return err
 	}
 
+	// leave the manifest and cache untouched, the pages of the failed schemas are missing
+	if len(problems) > 0 {
+		return &ConvertError{Problems: problems}
+	}
+
+	if err := c.saveManifest(); err != nil {
+		return err
+	}
+
+	return c.saveCache()
+}
+
+// loadManifest loads the files generated by the previous run from the destination
+func (c *Converter) loadManifest() (err error) {
+	c.generated, err = LoadManifest(filepath.Join(c.config.Destination, ManifestFile))
+	return err
+}
+
+// saveManifest prunes the files generated by the previous run that are no longer
+// produced and stores the files generated by the current run in the destination
+func (c *Converter) saveManifest() error {
+	if c.config.Prune {
+		if err := c.manifest.Prune(c.generated, c.config.Destination); err != nil {
+			return err
+		}
+	}
+	return c.manifest.Save(filepath.Join(c.config.Destination, ManifestFile))
+}
+
+// track records a file generated in the destination
+func (c *Converter) track(path string) {
+	rel, err := filepath.Rel(c.config.Destination, path)
+	if err != nil {
+		rel = path
+	}
+	c.manifest.Add(filepath.ToSlash(rel))
+}
+
+// generatedBefore reports whether a file in the destination was generated by the previous run
+func (c *Converter) generatedBefore(path string) bool {
+	rel, err := filepath.Rel(c.config.Destination, path)
+	return err == nil && c.generated.Has(filepath.ToSlash(rel))
+}
+
+// Load finds, loads and compiles the schemas in path. The schemas that compiled are
+// returned along with a *ConvertError describing the problems found.
+func (c *Converter) Load(path string) ([]*Schema, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	paths, err := FindFiles(path, c.config.Extension, c.config.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	var loaded []string
 	for _, path := range paths {
 		log.Infof("loading schema: %s", path)
 		if err := c.loadSchema(path); err != nil {
-			return err
+			problems = append(problems, ToProblems(StageLoad, path, err)...)
+			continue
 		}
+		loaded = append(loaded, path)
 	}
 
-	schemas, err := c.compileSchemas(paths)
+	schemas, err := c.compileSchemas(loaded)
 	if err != nil {
-		return err
+		problems = append(problems, err.(*ConvertError).Problems...)
 	}
 
-	for _, schema := range schemas {
-		c.converted[schema.Location] = true
+	if len(problems) > 0 {
+		return schemas, &ConvertError{Problems: problems}
+	}
+	return schemas, nil
+}
+
+// Document returns the raw json of the schema file at path, as it was loaded
+func (c *Converter) Document(path string) RawSchema {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.raw[path]
+}
+
+// loadCache loads the cache of the previous run from the destination
+func (c *Converter) loadCache() (err error) {
+	key, err := CacheKey(c.config)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute cache key")
 	}
 
+	c.cache = NewCache(key)
+	if !c.config.Cache {
+		return nil
+	}
+
+	c.previous, err = LoadCache(filepath.Join(c.config.Destination, CacheFile))
+	return err
+}
+
+// saveCache stores the cache of the current run in the destination
+func (c *Converter) saveCache() error {
+	if !c.config.Cache {
+		return nil
+	}
+	return c.cache.Save(filepath.Join(c.config.Destination, CacheFile))
+}
+
+// dependencies returns the schema documents a page is rendered from: the document of
+// the schema, the document it was found in, any document it references transitively and
+// the documents of the schemas referencing it, which are listed in its "Referenced by".
+func (c *Converter) dependencies(s *Schema) []string {
+	unique := map[string]bool{}
+	if document, ok := c.documents[s.Path]; ok {
+		unique[document] = true
+	}
+	s.WalkSchema(true, func(next *Schema) error {
+		unique[TrimAnchorPath(next.Location)] = true
+		for _, ref := range c.refs.ReferencedBy(next) {
+			unique[TrimAnchorPath(ref.Schema.Location)] = true
+		}
+		return nil
+	})
+
+	var documents []string
+	for document := range unique {
+		documents = append(documents, document)
+	}
+	sort.Strings(documents)
+	return documents
+}
+
+// planPages lists the pages to render for each schema and its definitions. Planning
+// happens sequentially so the pages, and therefore the output, do not depend on the
+// scheduling of the render workers.
+func (c *Converter) planPages(schemas []*Schema) ([]page, error) {
 	for _, schema := range schemas {
-		definitions := schema.Definitions()
-		if err := c.convertToMarkdown("_index", schema); err != nil {
-			return err
+		c.markConverted(schema.Location)
+	}
+
+	var pages []page
+	if c.config.SinglePage {
+		// single pages are written directly to the destination, so documents of the same
+		// name in different directories would share a page
+		planned := map[string]string{}
+		for _, schema := range schemas {
+			name := Slugify(FilenameWithoutExt(TrimAnchorPath(schema.Location)))
+			if other, ok := planned[name]; ok {
+				return nil, errors.Errorf("the pages of %s and %s would both be written to %s", other, schema.Location, filepath.Join(c.config.Destination, name+".md"))
+			}
+			planned[name] = schema.Location
+			pages = append(pages, page{name, schema})
+			c.planAnchors(schema, c.definitions(schema))
 		}
+		return pages, nil
+	}
 
+	for _, schema := range schemas {
+		pages = append(pages, page{"_index", schema})
+
+		// definitions are planned in the order of their location, so the definition whose
+		// page is suffixed for sharing its path does not depend on the order they are found in
+		definitions := c.definitions(schema)
+		sort.Slice(definitions, func(i, j int) bool { return definitions[i].Location < definitions[j].Location })
 		for _, def := range definitions {
-			if _, ok := c.converted[def.Location]; ok {
+			if c.isConverted(def.Location) {
 				continue
 			}
+			c.markConverted(def.Location)
 
 			name := FileName(def.Title, def.Location)
 			// Append weight to the filename if flag orderedfilepath is set
@@ -108,22 +404,230 @@ func (c *Converter) Convert(path string) error {
 				weight := GetWeight(c.order)(def.Path, def.Location)
 				name = fmt.Sprintf("%v-%v", GetFilenameWeight(weight), name)
 			}
-			if err := c.convertToMarkdown(name, def); err != nil {
-				return err
+			pages = append(pages, page{name, def})
+		}
+	}
+
+	// planned are the locations of the schemas of the planned pages by their path
+	planned := map[string]string{}
+	for i, p := range pages {
+		path := filepath.Join(c.pageDir(p.schema), p.name+".md")
+		if other, ok := planned[path]; ok {
+			if p.name == "_index" {
+				return nil, errors.Errorf("the pages of %s and %s would both be written to %s", other, p.schema.Location, path)
+			}
+			// definitions whose names slugify the same are numbered
+			name := p.name
+			for n := 2; planned[path] != ""; n++ {
+				name = fmt.Sprintf("%s-%d", p.name, n)
+				path = filepath.Join(c.pageDir(p.schema), name+".md")
+			}
+			pages[i].name = name
+		}
+		planned[path] = p.schema.Location
+
+		if err := c.createIndex(c.pageDir(p.schema)); err != nil {
+			return nil, err
+		}
+		c.pages[p.schema.Location] = path
+	}
+	return pages, nil
+}
+
+// planAnchors gives the sections of the single page of a schema unique ids: the anchor of
+// their schema, or the anchor of its json pointer when another section of the page already
+// uses it. The definitions are given their ids in the order of their location, so the ids do
+// not depend on the order the definitions are found in.
+func (c *Converter) planAnchors(schema *Schema, definitions []*Schema) {
+	sections := append([]*Schema{}, definitions...)
+	sort.Slice(sections, func(i, j int) bool { return sections[i].Location < sections[j].Location })
+	sections = append([]*Schema{schema}, sections...)
+
+	used := map[string]bool{}
+	for _, s := range sections {
+		anchor := Anchor(s.Schema)
+		if used[anchor] {
+			anchor = FirstNonEmpty(Slugify(Pointer(s.Location)), anchor)
+		}
+		unique := anchor
+		for i := 2; used[unique]; i++ {
+			unique = fmt.Sprintf("%s-%d", anchor, i)
+		}
+		used[unique] = true
+		c.anchors[s.Location] = unique
+	}
+}
+
+// anchor returns the id of the section of the schema within its single page
+func (c *Converter) anchor(s *jsonschema.Schema) string {
+	if anchor, ok := c.anchors[s.Location]; ok {
+		return anchor
+	}
+	return Anchor(s)
+}
+
+// renderPages renders the pages using a bounded pool of workers. The problems of all
+// the pages that failed, in page order, are returned as a *ConvertError.
+func (c *Converter) renderPages(pages []page) error {
+	jobs := c.config.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	errs := make([]error, len(pages))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				errs[i] = c.convertToMarkdown(pages[i].name, pages[i].schema)
+			}
+		}()
+	}
+
+	for i := range pages {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	var problems []Problem
+	for i, err := range errs {
+		if err != nil {
+			location := pages[i].schema.Location
+			path := FirstNonEmpty(FileFromURL(TrimAnchorPath(location)), pages[i].schema.Path)
+			for _, p := range ToProblems(StageRender, path, err) {
+				p.Pointer = FirstNonEmpty(p.Pointer, AnchorPath(location))
+				problems = append(problems, p)
 			}
 		}
 	}
 
+	if len(problems) > 0 {
+		return &ConvertError{Problems: problems}
+	}
 	return nil
 }
 
+func (c *Converter) markConverted(location string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.converted[location] = true
+}
+
+func (c *Converter) isConverted(location string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.converted[location]
+}
+
 // parseTemplates parses all gohtml templates from the embedded fs
 func (c *Converter) parseTemplates() (err error) {
-	c.template = template.New("").Funcs(FuncMap(c.config.ReferenceUrl(), c.patterns, c.order))
+	c.template = template.New("").Funcs(FuncMap(c.config.ReferenceUrl(), c.patterns, c.order)).Funcs(c.funcMap())
 	c.template, err = c.template.ParseFS(templates.Files, "*.gohtml")
 	if err != nil {
 		return errors.Wrap(err, "failed to parse templates")
 	}
+	return c.parseOverrides()
+}
+
+// parseOverrides parses the gohtml templates of the Templates directory, which replace the
+// embedded templates of the same name
+func (c *Converter) parseOverrides() error {
+	paths, err := TemplateOverrides(c.config.Templates)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		b, err := afero.ReadFile(AppFS, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read template: %s", path)
+		}
+		if _, err = c.template.New(filepath.Base(path)).Parse(string(b)); err != nil {
+			return errors.Wrapf(err, "failed to parse template: %s", path)
+		}
+	}
+	return nil
+}
+
+// funcMap returns the template functions which depend on the converter
+func (c *Converter) funcMap() template.FuncMap {
+	funcs := template.FuncMap{
+		"config":        func() Config { return c.config },
+		"frontMatter":   c.frontMatter,
+		"breakOut":      GetBreakOut(c.config.InlineDepth),
+		"findBreakouts": GetFindBreakouts(c.config.InlineDepth, c.patterns),
+		"referencedBy":  func(s *Schema) []Reference { return c.refs.ReferencedBy(s) },
+		"draft":         func(s *Schema) string { return c.draft(s.Location).Name },
+		"keywords":      c.findKeywords,
+		"renderKeyword": c.renderKeyword,
+		"components":    c.componentGroups,
+		"definitions":   c.definitions,
+		"union":         func(s *Schema) *Union { return FindUnion(s.Schema, c.rawSchema(s.Location)) },
+		"fallbacks":     c.pageFallbacks,
+		"indexFrontMatter": func(title string) (string, error) {
+			return c.config.FrontMatter.Render(c.config.FrontMatter.IndexFields(title))
+		},
+	}
+	// definitions are sections of the page of their schema rather than pages of their own
+	if c.config.SinglePage {
+		funcs["anchor"] = c.anchor
+		funcs["permalink"] = GetAnchorLink(c.anchor)
+	}
+	return funcs
+}
+
+// frontMatter renders the front matter of the page of a schema
+func (c *Converter) frontMatter(s *Schema, title string, weight int) (string, error) {
+	fm := c.config.FrontMatter
+	return fm.Render(fm.PageFields(title, weight, s.Description, c.rawSchema(s.Location)))
+}
+
+// draft returns the draft of the document of the schema at location, declared by its
+// $schema or configured
+func (c *Converter) draft(location string) Draft {
+	if url, ok := c.rawSchema(TrimAnchorPath(location))["$schema"].(string); ok {
+		if d, ok := FindDraft(url); ok {
+			return d
+		}
+	}
+	draft, _ := c.config.SchemaDraft()
+	return draft
+}
+
+// rawSchema returns the raw json of the schema at location, as it was loaded
+func (c *Converter) rawSchema(location string) RawSchema {
+	document := TrimAnchorPath(location)
+	for path, doc := range c.documents {
+		if doc != document {
+			continue
+		}
+
+		var value interface{} = map[string]interface{}(c.Document(path))
+		for _, token := range strings.Split(strings.TrimPrefix(Pointer(location), "/"), "/") {
+			if len(token) == 0 {
+				continue
+			}
+			switch v := value.(type) {
+			case map[string]interface{}:
+				value = v[unescapePointer(token)]
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(v) {
+					return nil
+				}
+				value = v[i]
+			default:
+				return nil
+			}
+		}
+
+		raw, _ := value.(map[string]interface{})
+		return raw
+	}
 	return nil
 }
 
@@ -140,26 +644,268 @@ func (c *Converter) loadSchema(path string) error {
 		return err
 	}
 
-	var schema RawSchema
+	c.mu.Lock()
+	c.sources[path] = Hash(string(b))
+	c.mu.Unlock()
+
+	if b, err = c.decodeDocument(path, b); err != nil {
+		return err
+	}
+
+	var schema, raw RawSchema
 	if err = json.Unmarshal(b, &schema); err != nil {
 		return errors.Wrapf(err, "failed to decode schema: %s", path)
 	}
+	_ = json.Unmarshal(b, &raw)
+	c.mu.Lock()
+	c.raw[path] = raw
+	c.mu.Unlock()
 
 	if c.config.Ordered || c.config.OrderedFilePath {
-		c.order[path] = orderedmap.New()
-		if err = json.Unmarshal(b, c.order[path]); err != nil {
+		order := orderedmap.New()
+		if err = json.Unmarshal(b, order); err != nil {
 			return errors.Wrapf(err, "failed to decode schema: %s", path)
 		}
+		c.mu.Lock()
+		c.order[path] = order
+		c.mu.Unlock()
+	}
+
+	// register the schema under its path as well as its $id, so it can be compiled by path
+	// and referenced by $id without loading it again
+	urls := []string{path}
+	if id := schema.Id(); len(id) > 0 {
+		urls = append(urls, id)
+	}
+	return c.addResource(schema, urls...)
+}
+
+// decodeDocument returns the json schema document of a file: yaml files are converted to
+// json, keeping the order of their keys, and OpenAPI and AsyncAPI documents are translated
+// into a json schema document whose component schemas are recorded as definitions. The bytes
+// of any other document are returned as they are.
+func (c *Converter) decodeDocument(path string, b []byte) ([]byte, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		var node yaml.Node
+		if err := yaml.Unmarshal(b, &node); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode yaml: %s", path)
+		}
+		if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+			return nil, errors.Errorf("failed to decode yaml: %s: not an object", path)
+		}
+		var err error
+		if b, err = yamlJSON(&node); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode yaml: %s", path)
+		}
 	}
+	if !bytes.Contains(b, []byte(`"openapi"`)) && !bytes.Contains(b, []byte(`"asyncapi"`)) {
+		return b, nil
+	}
+
+	var doc RawSchema
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode schema: %s", path)
+	}
+
+	var pointers []string
+	var err error
+	switch {
+	case IsOpenAPI(doc):
+		if doc, pointers, err = OpenAPISchema(doc); err != nil {
+			return nil, errors.Wrapf(err, "failed to translate OpenAPI document: %s", path)
+		}
+	case IsAsyncAPI(doc):
+		if doc, pointers, err = AsyncAPISchema(doc); err != nil {
+			return nil, errors.Wrapf(err, "failed to translate AsyncAPI document: %s", path)
+		}
+	default:
+		return b, nil
+	}
+	if len(pointers) > 0 {
+		c.mu.Lock()
+		c.components[path] = pointers
+		c.mu.Unlock()
+	}
+	return json.Marshal(doc)
+}
+
+// Components are the component schemas of a document of one kind, e.g. messages
+type Components struct {
+	// Name is the kind of the components, e.g. schemas, messages or channels
+	Name    string
+	Schemas []*Schema
+}
+
+// componentGroups returns the component schemas of the document of a root schema, grouped
+// by kind in the order they were translated. Only the page of the document lists them.
+func (c *Converter) componentGroups(s *Schema) []Components {
+	if len(AnchorPath(s.Location)) > 0 {
+		return nil
+	}
+
+	var groups []Components
+	for _, component := range c.compiled[s.Path] {
+		tokens := strings.Split(Pointer(component.Location), "/")
+		name := tokens[1]
+		if name == "components" && len(tokens) > 2 {
+			name = tokens[2]
+		}
+		if len(groups) == 0 || groups[len(groups)-1].Name != name {
+			groups = append(groups, Components{Name: name})
+		}
+		groups[len(groups)-1].Schemas = append(groups[len(groups)-1].Schemas, component)
+	}
+	return groups
+}
+
+// definitions returns the component schemas of the document of a root schema, followed by
+// the schemas it and its components reference, once each
+func (c *Converter) definitions(s *Schema) []*Schema {
+	var definitions []*Schema
+	unique := map[string]bool{s.Location: true}
+	add := func(schemas ...*Schema) {
+		for _, def := range schemas {
+			if !unique[def.Location] {
+				unique[def.Location] = true
+				definitions = append(definitions, def)
+			}
+		}
+	}
+
+	components := c.compiled[s.Path]
+	add(components...)
+	add(s.Definitions()...)
+	for _, component := range components {
+		add(component.Definitions()...)
+	}
+	return definitions
+}
+
+// loadURL loads a document referenced by the loaded schemas, recording its content hash so
+// the pages depending on it are rendered again when it changes
+func (c *Converter) loadURL(url string) (io.ReadCloser, error) {
+	r, err := jsonschema.LoadURL(url)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.resolved[url] = Hash(string(b))
+	c.mu.Unlock()
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+// AddResource adds the json schema in b to the compiler under url, applying the middleware
+func (c *Converter) AddResource(url string, b []byte) error {
+	var schema RawSchema
+	if err := json.Unmarshal(b, &schema); err != nil {
+		return errors.Wrapf(err, "failed to decode schema: %s", url)
+	}
+	return c.addResource(schema, url)
+}
 
+func (c *Converter) addResource(schema RawSchema, urls ...string) error {
 	c.applyMiddleware(schema)
-	b, err = json.Marshal(schema)
+	b, err := json.Marshal(schema)
 	if err != nil {
-		return errors.Wrapf(err, "failed to marshal schema: %s", path)
+		return errors.Wrapf(err, "failed to marshal schema: %s", urls[0])
 	}
 
-	url := FirstNonEmpty(schema.Id(), path)
-	return c.compiler.AddResource(url, bytes.NewReader(b))
+	for _, url := range urls {
+		if err = c.compiler.AddResource(url, bytes.NewReader(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile loads and compiles a single schema file
+func (c *Converter) LoadFile(path string) (*Schema, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if err := c.loadSchema(path); err != nil {
+		return nil, &ConvertError{Problems: ToProblems(StageLoad, path, err)}
+	}
+
+	return c.Compile(path)
+}
+
+// Compile compiles the schema added to the compiler under url
+func (c *Converter) Compile(url string) (*Schema, error) {
+	schemas, err := c.compileSchemas([]string{url})
+	if err != nil {
+		return nil, err
+	}
+	return schemas[0], nil
+}
+
+// Order returns the schema file at path decoded with its key order preserved, it is only
+// available when the Ordered or OrderedFilePath options are set
+func (c *Converter) Order(path string) *orderedmap.OrderedMap {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.order[path]
+}
+
+// Keyword returns the raw value of the keyword name of s, e.g. a custom keyword which the
+// compiled schema leaves out
+func (c *Converter) Keyword(s *jsonschema.Schema, name string) (interface{}, bool) {
+	value, ok := c.rawSchema(s.Location)[name]
+	return value, ok
+}
+
+// PropertyOrder returns the names of the properties of s in the order they are declared, it
+// is only available when the Ordered or OrderedFilePath options are set
+func (c *Converter) PropertyOrder(s *jsonschema.Schema) []string {
+	document := TrimAnchorPath(s.Location)
+	for path, doc := range c.documents {
+		if doc != document {
+			continue
+		}
+
+		order := c.Order(path)
+		if order == nil {
+			return nil
+		}
+		var value interface{} = order
+		tokens := append(strings.Split(strings.TrimPrefix(Pointer(s.Location), "/"), "/"), "properties")
+		for _, token := range tokens {
+			if len(token) == 0 {
+				continue
+			}
+			switch v := value.(type) {
+			case *orderedmap.OrderedMap:
+				value, _ = v.Get(unescapePointer(token))
+			case orderedmap.OrderedMap:
+				value, _ = v.Get(unescapePointer(token))
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(v) {
+					return nil
+				}
+				value = v[i]
+			default:
+				return nil
+			}
+		}
+
+		switch v := value.(type) {
+		case *orderedmap.OrderedMap:
+			return v.Keys()
+		case orderedmap.OrderedMap:
+			return v.Keys()
+		}
+		return nil
+	}
+	return nil
 }
 
 // applyMiddleware recursively walks through the json schema and applies the middleware
@@ -169,6 +915,7 @@ func (c *Converter) applyMiddleware(m map[string]interface{}) {
 			m[key] = fn(prop)
 		}
 	}
+	c.moveAdditional(m)
 
 	for _, prop := range m {
 		if _, ok := prop.(map[string]interface{}); ok {
@@ -177,87 +924,184 @@ func (c *Converter) applyMiddleware(m map[string]interface{}) {
 	}
 }
 
-// compileSchemas compiles each schema from their Path
+// compileSchemas compiles each schema from their Path. The schemas that compiled are
+// returned along with a *ConvertError describing those that did not.
 func (c *Converter) compileSchemas(paths []string) ([]*Schema, error) {
 	var schemas []*Schema
+	var problems []Problem
 	for _, path := range paths {
 		log.Debugf("compiling schema: %s", path)
 		schema, err := c.compiler.Compile(path)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to compile schema: %schema", path)
+			problems = append(problems, ToProblems(StageCompile, path, err)...)
+			continue
 		}
+		c.documents[path] = TrimAnchorPath(schema.Location)
 		schemas = append(schemas, ToSchema(schema, path))
+
+		for _, pointer := range c.components[path] {
+			component, err := c.compiler.Compile(path + pointer)
+			if err != nil {
+				problems = append(problems, ToProblems(StageCompile, path, err)...)
+				continue
+			}
+			c.compiled[path] = append(c.compiled[path], ToSchema(component, path))
+		}
+
+		draft := c.draft(schema.Location)
+		for _, warning := range DraftWarnings(c.Document(path), draft) {
+			log.Warnf("%s: %s", path, warning)
+		}
+	}
+
+	if len(problems) > 0 {
+		return schemas, &ConvertError{Problems: problems}
 	}
 	return schemas, nil
 }
 
 // convertToMarkdown executes the template to convert the schema to md
 func (c *Converter) convertToMarkdown(filename string, schema *Schema) error {
-	path := filepath.Join(c.config.Destination, FilePath(schema.Location))
+	path := c.pageDir(schema)
 	if err := c.createIndex(path); err != nil {
 		return err
 	}
 
-	log.Debugf("converting schema to md: %s", path)
 	filename = fmt.Sprintf("%s.md", filename)
 	path = filepath.Join(path, filename)
-	mdFile, err := AppFS.Create(path)
+	c.markConverted(schema.Location)
+	c.track(path)
+
+	dependencies := c.dependencies(schema)
+	if c.cache.Reuse(c.previous, path, dependencies) {
+		log.Debugf("skipping unchanged md: %s", path)
+		return nil
+	}
+
+	log.Debugf("converting schema to md: %s", path)
+	tmpl, err := c.pageTemplate(path)
 	if err != nil {
-		return errors.Wrapf(err, "failed to create md file: %s", path)
+		return err
 	}
 
-	defer mdFile.Close()
+	var buf bytes.Buffer
+	base := "base.gohtml"
+	if c.config.SinglePage {
+		base = "single.gohtml"
+	}
+	data := schema
+	if c.config.FlattenAllOf {
+		data = Flatten(schema)
+	}
+	if err := tmpl.ExecuteTemplate(&buf, base, data); err != nil {
+		return err
+	}
 
-	c.converted[schema.Location] = true
-	return c.template.ExecuteTemplate(mdFile, "base.gohtml", schema)
+	if _, err := WriteIfChanged(path, buf.Bytes()); err != nil {
+		return err
+	}
+
+	c.cache.Record(path, Hash(buf.String()), dependencies)
+	return nil
+}
+
+// pageTemplate returns the template to render the page at path with. Links relative to
+// the page need a permalink function of their own, so the template is cloned for them.
+func (c *Converter) pageTemplate(path string) (*template.Template, error) {
+	strategy, err := c.config.LinkStrategy()
+	if err != nil || strategy == LinksBaseURL || c.config.SinglePage {
+		return c.template, err
+	}
+
+	tmpl, err := c.template.Clone()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to clone templates")
+	}
+	return tmpl.Funcs(template.FuncMap{
+		"permalink": GetRelativeLink(c.config.Destination, path, c.pages, strategy == LinksRelative),
+	}), nil
+}
+
+// pageDir returns the directory the page of the schema is written to, single pages are
+// written directly to the destination
+func (c *Converter) pageDir(schema *Schema) string {
+	if c.config.SinglePage {
+		return c.config.Destination
+	}
+	return filepath.Join(c.config.Destination, FilePath(schema.Location))
 }
 
 // createIndex creates a _index.md file for each directory in the Path
 func (c *Converter) createIndex(path string) error {
 	log.Debugf("creating index: %s", path)
 	path = filepath.Clean(path)
-	if c.config.Destination == path {
+	if rel, err := filepath.Rel(filepath.Clean(c.config.Destination), path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+
+	c.mu.Lock()
+	created := c.indexes[path]
+	c.indexes[path] = true
+	c.mu.Unlock()
+	if created {
 		return nil
 	}
 
 	indexPath := filepath.Join(path, "_index.md")
 	if _, err := AppFS.Stat(indexPath); !os.IsNotExist(err) {
-		return nil
+		if c.generatedBefore(indexPath) {
+			c.track(indexPath)
+		}
+		return c.createIndex(filepath.Dir(path))
 	}
 
 	if err := AppFS.MkdirAll(path, fs.ModePerm); err != nil {
 		return errors.Wrapf(err, "failed to created index directory: %s", path)
 	}
 
-	dir := filepath.Base(path)
-	fm := fmt.Sprintf("---\ntitle: %s\n---", dir)
+	fm, err := c.config.FrontMatter.Render(c.config.FrontMatter.IndexFields(filepath.Base(path)))
+	if err != nil {
+		return err
+	}
 	if err := afero.WriteFile(AppFS, indexPath, []byte(fm), os.ModePerm); err != nil {
 		return err
 	}
+	c.track(indexPath)
 
 	return c.createIndex(filepath.Dir(path))
 }
 
 // Regex lookahead/behind is not supported in Go and the schema will not compile if the regex is invalid.
-// As a workaround, replace the regex with a hash and use the hash to load it from the template
+// The patterns the regexp package rejects are replaced by a placeholder, which is used to load them
+// from the template, and are enforced by the regex engine through the pattern extension.
 // See https://github.com/santhosh-tekuri/jsonschema/issues/31
 func (c *Converter) middleware() map[string]middlewareFunc {
 	return map[string]middlewareFunc{
 		"patternProperties": func(prop interface{}) interface{} {
-			props := prop.(map[string]interface{})
+			props, ok := prop.(map[string]interface{})
+			if !ok {
+				return prop
+			}
 			patterns := map[string]interface{}{}
 			for k, v := range props {
-				h := Hash(k)
-				c.patterns[h] = k
-				patterns[h] = v
+				if _, err := regexp.Compile(k); err == nil {
+					patterns[k] = v
+					continue
+				}
+				patterns[c.fallback(k, true)] = v
 			}
 			return patterns
 		},
 		"pattern": func(prop interface{}) interface{} {
-			pattern := prop.(string)
-			h := Hash(pattern)
-			c.patterns[h] = pattern
-			return h
+			// a property named pattern
+			pattern, ok := prop.(string)
+			if !ok {
+				return prop
+			}
+			if _, err := regexp.Compile(pattern); err == nil {
+				return pattern
+			}
+			return c.fallback(pattern, false)
 		},
 	}
 }
@@ -36,24 +36,30 @@ func TestNewConverter(t *testing.T) {
 }
 
 func TestConverter_Clean(t *testing.T) {
-	c := NewConverter(config)
-	rootpath := "."
-	test_output := filepath.Join(rootpath, "test_output")
-	test_file, err := AppFS.Create(test_output)
-	assert.Nil(t, err)
-	defer test_file.Close()
+	cfg := config
+	cfg.Destination = "/clean"
+	writeSchema(t, "/clean/schema/_index.md", "generated")
+	writeSchema(t, "/clean/schema/definitions/address.md", "generated")
+	writeSchema(t, "/clean/notes.md", "kept")
+	writeSchema(t, "/clean/"+CacheFile, "{}")
+	writeSchema(t, "/clean/"+ManifestFile, `{"files": ["schema/_index.md", "schema/definitions/address.md"]}`)
 
-	c.config.Destination = test_output
-	exists, err := afero.Exists(AppFS, test_output)
+	assert.Nil(t, NewConverter(cfg).Clean())
+	assert.Equal(t, map[string]string{"notes.md": "kept"}, readTree(t, cfg.Destination))
+	exists, err := afero.DirExists(AppFS, "/clean/schema")
 	assert.Nil(t, err)
-	assert.True(t, exists)
+	assert.False(t, exists)
 
-	err = c.Clean()
+	// entries leading outside the destination are not removed
+	writeSchema(t, "/outside/notes.md", "kept")
+	writeSchema(t, "/clean/"+ManifestFile, `{"files": ["../outside/notes.md", "/outside/notes.md"]}`)
+	assert.Nil(t, NewConverter(cfg).Clean())
+	exists, err = afero.Exists(AppFS, "/outside/notes.md")
 	assert.Nil(t, err)
+	assert.True(t, exists)
 
-	exists, err = afero.Exists(AppFS, test_output)
-	assert.Nil(t, err)
-	assert.False(t, exists)
+	cfg.Destination = "/clean-missing"
+	assert.Nil(t, NewConverter(cfg).Clean())
 }
 
 func TestConverter_Convert(t *testing.T) {
@@ -62,6 +68,351 @@ func TestConverter_Convert(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestConverter_ConvertParallel(t *testing.T) {
+	loadFixtures(t)
+	sequential := config
+	sequential.Destination = "/sequential/reference"
+	sequential.Jobs = 1
+	assert.Nil(t, NewConverter(sequential).Convert(filepath.Join(rootPath, "test")))
+
+	parallel := config
+	parallel.Destination = "/parallel/reference"
+	parallel.Jobs = 8
+	assert.Nil(t, NewConverter(parallel).Convert(filepath.Join(rootPath, "test")))
+
+	assert.Equal(t, readTree(t, sequential.Destination), readTree(t, parallel.Destination))
+}
+
+func TestConverter_ConvertDuplicatePages(t *testing.T) {
+	// definitions whose titles slugify the same are numbered
+	writeSchema(t, "/duplicate-pages/shop.schema.json", `{
+  "title": "Shop",
+  "type": "object",
+  "properties": {"billing": {"$ref": "#/definitions/billing"}, "shipping": {"$ref": "#/definitions/shipping"}},
+  "definitions": {
+    "billing": {"title": "Address", "type": "object", "properties": {"street": {"type": "string"}}},
+    "shipping": {"title": "address", "type": "object", "properties": {"city": {"type": "string"}}}
+  }
+}`)
+	cfg := config
+	cfg.Destination = "/duplicate-pages-output"
+	cfg.Jobs = 8
+	assert.Nil(t, NewConverter(cfg).Convert("/duplicate-pages"))
+
+	tree := readTree(t, cfg.Destination)
+	assert.Contains(t, tree["shop-schema/definitions/address.md"], "street")
+	assert.Contains(t, tree["shop-schema/definitions/address-2.md"], "city")
+
+	// documents whose pages share a path are rejected
+	writeSchema(t, "/duplicate-documents/a/order.schema.json", `{"title": "A", "type": "object"}`)
+	writeSchema(t, "/duplicate-documents/b/order.schema.json", `{"title": "B", "type": "object"}`)
+	cfg.Destination = "/duplicate-documents-output"
+	cfg.Recursive = true
+	err := NewConverter(cfg).Convert("/duplicate-documents")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "would both be written to")
+}
+
+func TestConverter_ConvertIncremental(t *testing.T) {
+	loadFixtures(t)
+	cfg := config
+	cfg.Destination = "/incremental"
+	cfg.Cache = true
+	assert.Nil(t, NewConverter(cfg).Convert(filepath.Join(rootPath, "test")))
+
+	cache, err := LoadCache(filepath.Join(cfg.Destination, CacheFile))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, cache.Pages)
+
+	sample := filepath.Join(cfg.Destination, "sample-schema", "_index.md")
+	ref := filepath.Join(cfg.Destination, "ref-schema", "_index.md")
+
+	// the pages of unchanged schemas are carried over rather than rendered
+	c := NewConverter(cfg)
+	assert.Nil(t, c.Convert(filepath.Join(rootPath, "test")))
+	assert.True(t, c.cache.reused[sample])
+	assert.True(t, c.cache.reused[ref])
+
+	// while the pages depending on a changed schema, which sample references, are rendered
+	refSchema := filepath.Join(rootPath, "test", "ref.schema.json")
+	writeSchema(t, refSchema, `{"description": "changed", "type": "object"}`)
+	defer loadFixtures(t)
+	c = NewConverter(cfg)
+	assert.Nil(t, c.Convert(filepath.Join(rootPath, "test")))
+	assert.False(t, c.cache.reused[sample])
+	assert.False(t, c.cache.reused[ref])
+
+	contains, err := afero.FileContainsBytes(AppFS, ref, []byte("changed"))
+	assert.Nil(t, err)
+	assert.True(t, contains)
+}
+
+func TestConverter_ConvertIncrementalExternal(t *testing.T) {
+	// the compiler loads the documents referenced outside the loaded schemas from disk
+	external := filepath.Join(t.TempDir(), "address.json")
+	assert.Nil(t, os.WriteFile(external, []byte(`{"title": "Address", "type": "object", "description": "before"}`), os.ModePerm))
+
+	cfg := config
+	cfg.Destination = "/incremental-external"
+	cfg.Cache = true
+	order := filepath.Join(cfg.Destination, "order-schema", "_index.md")
+	writeSchema(t, "/external/order.schema.json", `{"title": "Order", "type": "object", "properties": {"address": {"$ref": "file://`+filepath.ToSlash(external)+`"}}}`)
+	assert.Nil(t, NewConverter(cfg).Convert("/external"))
+
+	c := NewConverter(cfg)
+	assert.Nil(t, c.Convert("/external"))
+	assert.True(t, c.cache.reused[order])
+
+	// changing the referenced document renders the pages depending on it again
+	assert.Nil(t, os.WriteFile(external, []byte(`{"title": "Address", "type": "object", "description": "after"}`), os.ModePerm))
+	c = NewConverter(cfg)
+	assert.Nil(t, c.Convert("/external"))
+	assert.False(t, c.cache.reused[order])
+}
+
+func TestConverter_ConvertIncrementalReferences(t *testing.T) {
+	cfg := config
+	cfg.Destination = "/incremental-references"
+	cfg.Cache = true
+	b := filepath.Join(cfg.Destination, "b-schema", "_index.md")
+
+	writeSchema(t, "/references/a.schema.json", `{"title": "A", "type": "object", "properties": {"b": {"$ref": "b.schema.json"}}}`)
+	writeSchema(t, "/references/b.schema.json", `{"title": "B", "type": "object"}`)
+	assert.Nil(t, NewConverter(cfg).Convert("/references"))
+	contains, err := afero.FileContainsBytes(AppFS, b, []byte("**Referenced by:**"))
+	assert.Nil(t, err)
+	assert.True(t, contains)
+
+	// removing the reference renders the page of b again, although b is unchanged
+	writeSchema(t, "/references/a.schema.json", `{"title": "A", "type": "object"}`)
+	assert.Nil(t, NewConverter(cfg).Convert("/references"))
+	contains, err = afero.FileContainsBytes(AppFS, b, []byte("**Referenced by:**"))
+	assert.Nil(t, err)
+	assert.False(t, contains)
+
+	// and so does adding it back
+	writeSchema(t, "/references/a.schema.json", `{"title": "A", "type": "object", "properties": {"b": {"$ref": "b.schema.json"}}}`)
+	assert.Nil(t, NewConverter(cfg).Convert("/references"))
+	contains, err = afero.FileContainsBytes(AppFS, b, []byte("**Referenced by:**"))
+	assert.Nil(t, err)
+	assert.True(t, contains)
+}
+
+func TestConverter_ConvertPrune(t *testing.T) {
+	loadFixtures(t)
+	cfg := config
+	cfg.Destination = "/prune"
+	cfg.Prune = true
+
+	handWritten := filepath.Join(cfg.Destination, "ref-schema", "notes.md")
+	writeSchema(t, handWritten, "notes")
+	assert.Nil(t, NewConverter(cfg).Convert(filepath.Join(rootPath, "test")))
+
+	refSchema := filepath.Join(rootPath, "test", "ref.schema.json")
+	assert.Nil(t, AppFS.Remove(refSchema))
+	defer loadFixtures(t)
+
+	sampleSchema := filepath.Join(rootPath, "test", "sample.schema.json")
+	writeSchema(t, sampleSchema, `{"title": "Sample", "type": "object"}`)
+	assert.Nil(t, NewConverter(cfg).Convert(filepath.Join(rootPath, "test")))
+
+	files := readTree(t, cfg.Destination)
+	assert.Contains(t, files, "ref-schema/notes.md")
+	assert.NotContains(t, files, "ref-schema/_index.md")
+	assert.Contains(t, files, "sample-schema/_index.md")
+}
+
+func TestConverter_ConvertOrdered(t *testing.T) {
+	// the enum value must not be mistaken for an OpenAPI document, whose keys are reordered
+	writeSchema(t, "/ordered/kind.schema.json", `{
+  "title": "kind",
+  "type": "object",
+  "properties": {"kind": {"enum": ["openapi"]}, "ref": {"$ref": "#/$defs/zeta"}, "other": {"$ref": "#/$defs/alpha"}},
+  "$defs": {"zeta": {"type": "string"}, "alpha": {"type": "number"}}
+}`)
+	writeSchema(t, "/ordered/kind.yaml", `title: kind
+type: object
+properties:
+  ref: {$ref: "#/$defs/zeta"}
+  other: {$ref: "#/$defs/alpha"}
+$defs:
+  zeta: {type: string}
+  alpha: {type: number}
+`)
+	for path, section := range map[string]string{"/ordered/kind.schema.json": "kind-schema", "/ordered/kind.yaml": "kind"} {
+		cfg := config
+		cfg.Destination = "/ordered-out"
+		cfg.OrderedFilePath = true
+		assert.Nil(t, NewConverter(cfg).Convert(path))
+
+		files := readTree(t, cfg.Destination)
+		assert.Contains(t, files, section+"/01-zeta.md", path)
+		assert.Contains(t, files, section+"/02-alpha.md", path)
+		assert.Nil(t, AppFS.RemoveAll(cfg.Destination))
+	}
+}
+
+func TestConverter_ConvertSinglePage(t *testing.T) {
+	loadFixtures(t)
+	cfg := config
+	cfg.Destination = "/single"
+	cfg.SinglePage = true
+	assert.Nil(t, NewConverter(cfg).Convert(filepath.Join(rootPath, "test")))
+
+	files := readTree(t, cfg.Destination)
+	assert.Contains(t, files, "sample-schema.md")
+	assert.Contains(t, files, "ref-schema.md")
+	assert.NotContains(t, files, "sample-schema/_index.md")
+
+	page := files["sample-schema.md"]
+	assert.Contains(t, page, `<a id="ref-schema"></a>`)
+	assert.Contains(t, page, "[ref.schema](#ref-schema)")
+	assert.NotContains(t, page, "{{%baseurl%}}")
+}
+
+func TestConverter_ConvertSinglePageAnchors(t *testing.T) {
+	// definitions sharing a title get the anchor of their pointer instead
+	writeSchema(t, "/single-anchors/shop.schema.json", `{
+  "title": "Shop",
+  "type": "object",
+  "properties": {"billing": {"$ref": "#/$defs/billing"}, "shipping": {"$ref": "#/$defs/shipping"}},
+  "$defs": {
+    "billing": {"title": "Address", "type": "object", "properties": {"street": {"type": "string"}}},
+    "shipping": {"title": "Address", "type": "object", "properties": {"city": {"type": "string"}}}
+  }
+}`)
+	cfg := config
+	cfg.Destination = "/single-anchors-output"
+	cfg.SinglePage = true
+	assert.Nil(t, NewConverter(cfg).Convert("/single-anchors"))
+
+	page := readTree(t, cfg.Destination)["shop-schema.md"]
+	assert.Contains(t, page, `<a id="address"></a>`)
+	assert.Contains(t, page, `<a id="defs-shipping"></a>`)
+	assert.Contains(t, page, "| billing | [Address](#address) |")
+	assert.Contains(t, page, "| shipping | [Address](#defs-shipping) |")
+
+	// documents of the same name in different directories are rejected
+	writeSchema(t, "/single-documents/a/order.schema.json", `{"title": "A", "type": "object"}`)
+	writeSchema(t, "/single-documents/b/order.schema.json", `{"title": "B", "type": "object"}`)
+	cfg.Destination = "/single-documents-output"
+	cfg.Recursive = true
+	err := NewConverter(cfg).Convert("/single-documents")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "would both be written to")
+}
+
+func TestConverter_ConvertLinks(t *testing.T) {
+	loadFixtures(t)
+	cfg := config
+	cfg.Destination = "/links"
+	cfg.Links = LinksRelref
+	assert.Nil(t, NewConverter(cfg).Convert(filepath.Join(rootPath, "test")))
+
+	page := readTree(t, cfg.Destination)["sample-schema/_index.md"]
+	assert.Contains(t, page, `[ref.schema]({{< relref "../ref-schema/_index.md#ref-schema" >}})`)
+	assert.NotContains(t, page, "{{%baseurl%}}")
+
+	// definitions are linked to their own page, named after its weight
+	writeSchema(t, "/links-ordered/kind.schema.json", `{
+  "title": "kind",
+  "type": "object",
+  "properties": {"ref": {"$ref": "#/$defs/zeta"}},
+  "$defs": {"zeta": {"type": "string"}}
+}`)
+	cfg.Destination = "/links-ordered-out"
+	cfg.Links = LinksRelative
+	cfg.OrderedFilePath = true
+	assert.Nil(t, NewConverter(cfg).Convert("/links-ordered"))
+
+	files := readTree(t, cfg.Destination)
+	assert.Contains(t, files["kind-schema/_index.md"], "[zeta](01-zeta.md#zeta)")
+	assert.Contains(t, files["kind-schema/01-zeta.md"], "[kind](_index.md#kind)")
+}
+
+func TestConverter_ConvertInlineDepth(t *testing.T) {
+	writeSchema(t, "/inline-depth/deep.schema.json", deepSchema)
+	cfg := config
+	cfg.Destination = "/inline-depth-output"
+	cfg.InlineDepth = 2
+	cfg.Collapse = true
+	assert.Nil(t, NewConverter(cfg).Convert("/inline-depth"))
+
+	page := readTree(t, cfg.Destination)["deep-schema/_index.md"]
+	assert.Contains(t, page, "| a.b | [Object](#")
+	assert.Contains(t, page, "<summary>a.b</summary>")
+	assert.Contains(t, page, "| a.b.c.d | String |")
+	assert.Contains(t, page, "| a.list[].e | Integer |")
+}
+
+const keywordsSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Keywords",
+  "type": "object",
+  "properties": {
+    "labels": {
+      "type": "object",
+      "description": "Free-form labels",
+      "additionalProperties": {"type": "string", "description": "A label value"}
+    },
+    "point": {
+      "type": "array",
+      "prefixItems": [{"type": "number", "description": "x"}, {"type": "number", "description": "y"}],
+      "items": false,
+      "contains": {"type": "number", "minimum": 0},
+      "minContains": 2
+    },
+    "strict": {"type": "object", "properties": {"a": {"type": "string"}}, "unevaluatedProperties": false},
+    "loose": {"type": "array", "unevaluatedItems": {"type": "string"}}
+  },
+  "additionalProperties": false
+}`
+
+func TestConverter_ConvertKeywords(t *testing.T) {
+	writeSchema(t, "/keywords/keywords.schema.json", keywordsSchema)
+	cfg := config
+	cfg.Destination = "/keywords-output"
+	assert.Nil(t, NewConverter(cfg).Convert("/keywords"))
+
+	page := readTree(t, cfg.Destination)["keywords-schema/_index.md"]
+	for _, expected := range []string{
+		"**Object:**<br>No additional properties allowed",
+		"| labels.(additional) | String | A label value |  |",
+		"| loose[unevaluated] | String |  |  |",
+		"| point | Array |  | **Array:**<br>Min Contains: 2<br>No additional items allowed |",
+		"| point[0] | Number | x |  |",
+		"| point[1] | Number | y |  |",
+		"| point[contains] | Number |",
+		"| strict | Object |  | **Object:**<br>No unevaluated properties allowed |",
+	} {
+		assert.Contains(t, page, expected)
+	}
+	assert.NotContains(t, page, "| point[] |")
+}
+
+func TestConverter_ConvertProblems(t *testing.T) {
+	writeSchema(t, "/problems/a.schema.json", `{"type": "object"}`)
+	writeSchema(t, "/problems/b.schema.json", `{"type": 5}`)
+	writeSchema(t, "/problems/c.schema.json", `{"type":`)
+
+	cfg := config
+	cfg.Destination = "/problems-output"
+	err := NewConverter(cfg).Convert("/problems")
+	assert.IsType(t, &ConvertError{}, err)
+
+	stages := map[string]string{}
+	for _, p := range err.(*ConvertError).Problems {
+		stages[p.File] = p.Stage
+	}
+	assert.Equal(t, map[string]string{
+		"/problems/b.schema.json": StageCompile,
+		"/problems/c.schema.json": StageLoad,
+	}, stages)
+
+	exist, _ := afero.Exists(AppFS, "/problems-output/a-schema/_index.md")
+	assert.True(t, exist)
+}
+
 func TestConverter_parseTemplates(t *testing.T) {
 	c := NewConverter(config)
 	err := c.parseTemplates()
@@ -71,8 +422,9 @@ func TestConverter_parseTemplates(t *testing.T) {
 	templates := []string{
 		"any.gohtml", "base.gohtml",
 		"number.gohtml", "property.gohtml",
-		"string.gohtml", "typeof.gohtml", "array.gohtml",
-		"object.gohtml", "schema.gohtml",
+		"string.gohtml", "inline.gohtml", "array.gohtml",
+		"object.gohtml", "schema.gohtml", "sample.gohtml",
+		"single.gohtml", "index.gohtml", "references.gohtml", "components.gohtml",
 	}
 
 	for _, template := range templates {
@@ -125,22 +477,31 @@ func TestConverter_createIndex(t *testing.T) {
 
 func TestConverter_applyMiddleware(t *testing.T) {
 	c := NewConverter(config)
-	h := Hash("a")
+	lookahead := "^(?!a)"
 	rawSchema := map[string]interface{}{
-		"pattern": "a",
+		"pattern": lookahead,
 		"patternProperties": map[string]interface{}{
-			"a": 1,
+			"a":       1,
+			lookahead: 2,
+		},
+		"properties": map[string]interface{}{
+			"pattern": map[string]interface{}{"type": "string"},
 		},
 	}
 
 	actual := map[string]interface{}{
-		"pattern": h,
+		"pattern": "(?:" + Hash(lookahead) + ")?",
 		"patternProperties": map[string]interface{}{
-			h: 1,
+			"a":             1,
+			Hash(lookahead): 2,
+		},
+		"properties": map[string]interface{}{
+			"pattern": map[string]interface{}{"type": "string"},
 		},
 	}
 	c.applyMiddleware(rawSchema)
 	assert.Equal(t, rawSchema, actual)
+	assert.Equal(t, map[string]bool{lookahead: true}, c.fallbacks)
 }
 
 func TestConverter_middleware(t *testing.T) {
@@ -153,12 +514,14 @@ func TestConverter_middleware(t *testing.T) {
 func TestConverter_patternPropertyMiddleware(t *testing.T) {
 	c := NewConverter(config)
 	m := c.middleware()
-	h := Hash("a")
+	h := Hash("(?<=a)b")
 	res := m["patternProperties"](map[string]interface{}{
-		"a": 1,
+		"a":       1,
+		"(?<=a)b": 2,
 	})
 	if val, ok := res.(map[string]interface{}); ok {
-		assert.NotNil(t, val[h])
+		assert.Equal(t, 1, val["a"])
+		assert.Equal(t, 2, val[h])
 		return
 	}
 	t.Fail()
@@ -167,10 +530,12 @@ func TestConverter_patternPropertyMiddleware(t *testing.T) {
 func TestConverter_patternMiddleware(t *testing.T) {
 	c := NewConverter(config)
 	m := c.middleware()
-	h := Hash("a")
-	res := m["pattern"]("a")
+	assert.Equal(t, "a", m["pattern"]("a"))
+
+	h := Hash("(?=a)")
+	res := m["pattern"]("(?=a)")
 	if val, ok := res.(string); ok {
-		assert.Equal(t, h, val)
+		assert.Equal(t, "(?:"+h+")?", val)
 		return
 	}
 	t.Fail()
@@ -188,6 +553,36 @@ func validatePath(t *testing.T, root, path string) {
 	validatePath(t, root, filepath.Dir(path))
 }
 
+// loadFixtures copies the test schemas into AppFS
+func loadFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(rootPath, "test", "*.json"))
+	assert.Nil(t, err)
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		writeSchema(t, path, string(b))
+	}
+}
+
+// readTree returns the content of each file under root keyed by its relative path
+func readTree(t *testing.T, root string) map[string]string {
+	files := map[string]string{}
+	err := afero.Walk(AppFS, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		b, err := afero.ReadFile(AppFS, path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files[rel] = string(b)
+		return nil
+	})
+	assert.Nil(t, err)
+	return files
+}
+
 func writeSchema(t *testing.T, path string, content string) {
 	err := afero.WriteFile(AppFS, path, []byte(content), os.ModePerm)
 	assert.Nil(t, err)
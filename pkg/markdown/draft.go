@@ -0,0 +1,109 @@
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Draft is a version of the json schema specification
+type Draft struct {
+	// Name is the name the draft is selected and shown by, e.g. 2020-12
+	Name string
+
+	// Version orders the drafts, e.g. 2020
+	Version int
+
+	// URL is the $schema of the meta schema of the draft
+	URL string
+
+	draft *jsonschema.Draft
+}
+
+// Drafts are the supported drafts, oldest first
+var Drafts = []Draft{
+	{"4", 4, "http://json-schema.org/draft-04/schema#", jsonschema.Draft4},
+	{"6", 6, "http://json-schema.org/draft-06/schema#", jsonschema.Draft6},
+	{"7", 7, "http://json-schema.org/draft-07/schema#", jsonschema.Draft7},
+	{"2019-09", 2019, "https://json-schema.org/draft/2019-09/schema", jsonschema.Draft2019},
+	{"2020-12", 2020, "https://json-schema.org/draft/2020-12/schema", jsonschema.Draft2020},
+}
+
+// DefaultDraft is the draft of the schemas lacking $schema when none is configured
+var DefaultDraft = Drafts[len(Drafts)-1]
+
+// FindDraft returns the draft of the meta schema url, e.g. http://json-schema.org/draft-07/schema#
+func FindDraft(url string) (Draft, bool) {
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), "#")
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "http://"), "https://")
+	if url == "json-schema.org/schema" {
+		return DefaultDraft, true
+	}
+	for _, d := range Drafts {
+		if strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(d.URL, "http://"), "https://"), "#") == url {
+			return d, true
+		}
+	}
+	return Draft{}, false
+}
+
+// keywordDrafts are the versions of the first and last drafts which evaluate a keyword,
+// keywords evaluated by every draft are left out
+var keywordDrafts = map[string][2]int{
+	"const":                 {6, 2020},
+	"contains":              {6, 2020},
+	"propertyNames":         {6, 2020},
+	"examples":              {6, 2020},
+	"if":                    {7, 2020},
+	"then":                  {7, 2020},
+	"else":                  {7, 2020},
+	"contentMediaType":      {7, 2020},
+	"contentEncoding":       {7, 2020},
+	"$comment":              {7, 2020},
+	"$defs":                 {2019, 2020},
+	"$anchor":               {2019, 2020},
+	"dependentRequired":     {2019, 2020},
+	"dependentSchemas":      {2019, 2020},
+	"unevaluatedProperties": {2019, 2020},
+	"unevaluatedItems":      {2019, 2020},
+	"minContains":           {2019, 2020},
+	"maxContains":           {2019, 2020},
+	"$recursiveRef":         {2019, 2019},
+	"$recursiveAnchor":      {2019, 2019},
+	"prefixItems":           {2020, 2020},
+	"$dynamicRef":           {2020, 2020},
+	"$dynamicAnchor":        {2020, 2020},
+	"dependencies":          {4, 7},
+	"additionalItems":       {4, 2019},
+}
+
+// The keywords whose values are a schema, a list of schemas, or a map of schemas
+var (
+	schemaKeywords = []string{"additionalProperties", "additionalItems", "unevaluatedProperties",
+		"unevaluatedItems", "items", "contains", "propertyNames", "not", "if", "then", "else"}
+	schemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems", "items"}
+	schemaMapKeywords  = []string{"properties", "patternProperties", "$defs", "definitions",
+		"dependentSchemas", "dependencies"}
+)
+
+// DraftWarnings returns a warning for each keyword of the raw schema, and its subschemas,
+// which the draft ignores, e.g. prefixItems in draft 7
+func DraftWarnings(raw RawSchema, draft Draft) []string {
+	var warnings []string
+	raw.Walk(func(schema RawSchema, pointer string) {
+		var keys []string
+		for key := range schema {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if versions, ok := keywordDrafts[key]; ok && (draft.Version < versions[0] || draft.Version > versions[1]) {
+				warnings = append(warnings, fmt.Sprintf("%s/%s is ignored by draft %s", pointer, escapePointer(key), draft.Name))
+			}
+		}
+	})
+	return warnings
+}
@@ -0,0 +1,66 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDraft(t *testing.T) {
+	testCases := map[string]string{
+		"http://json-schema.org/draft-04/schema#":      "4",
+		"http://json-schema.org/draft-07/schema":       "7",
+		"https://json-schema.org/draft/2019-09/schema": "2019-09",
+		"https://json-schema.org/draft/2020-12/schema": "2020-12",
+		"https://json-schema.org/schema":               "2020-12",
+	}
+
+	for url, expected := range testCases {
+		d, ok := FindDraft(url)
+		assert.True(t, ok, url)
+		assert.Equal(t, expected, d.Name)
+	}
+
+	_, ok := FindDraft("https://example.com/schema")
+	assert.False(t, ok)
+}
+
+func TestDraftWarnings(t *testing.T) {
+	draft7, _ := FindDraft("http://json-schema.org/draft-07/schema#")
+	raw := RawSchema{
+		"$defs": map[string]interface{}{
+			"point": map[string]interface{}{"prefixItems": []interface{}{}},
+		},
+		"properties": map[string]interface{}{
+			// a property named after a keyword is not a keyword
+			"prefixItems": map[string]interface{}{"type": "string", "const": "a"},
+			"list":        map[string]interface{}{"items": map[string]interface{}{"unevaluatedProperties": false}},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"#/$defs is ignored by draft 7",
+		"#/properties/list/items/unevaluatedProperties is ignored by draft 7",
+		"#/$defs/point/prefixItems is ignored by draft 7",
+	}, DraftWarnings(raw, draft7))
+
+	assert.Equal(t, []string{"#/dependencies is ignored by draft 2020-12"},
+		DraftWarnings(RawSchema{"dependencies": map[string]interface{}{}}, DefaultDraft))
+	assert.Empty(t, DraftWarnings(RawSchema{"prefixItems": []interface{}{}}, DefaultDraft))
+}
+
+func TestConverter_ConvertDraft(t *testing.T) {
+	writeSchema(t, "/draft/declared.schema.json", `{"$schema": "http://json-schema.org/draft-07/schema#", "title": "Declared"}`)
+	writeSchema(t, "/draft/missing.schema.json", `{"title": "Missing"}`)
+	cfg := config
+	cfg.Destination = "/draft-output"
+	cfg.Draft = "2019-09"
+	assert.Nil(t, NewConverter(cfg).Convert("/draft"))
+
+	tree := readTree(t, cfg.Destination)
+	assert.Contains(t, tree["declared-schema/_index.md"], "**Draft:** 7")
+	assert.Contains(t, tree["missing-schema/_index.md"], "**Draft:** 2019-09")
+
+	cfg.Draft = "3"
+	assert.NotNil(t, NewConverter(cfg).Convert("/draft"))
+}
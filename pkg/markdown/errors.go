@@ -0,0 +1,274 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/spf13/afero"
+)
+
+// The stages of a conversion in which a problem can occur
+const (
+	StageLoad    = "load"
+	StageCompile = "compile"
+	StageRender  = "render"
+)
+
+// Problem describes a single failure found while converting the schemas
+type Problem struct {
+	Stage   string `json:"stage"`
+	File    string `json:"file"`
+	Pointer string `json:"pointer,omitempty"`
+	Keyword string `json:"keyword,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Cause   string `json:"cause"`
+}
+
+func (p Problem) Error() string {
+	location := p.File
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, p.Line)
+	}
+	if len(p.Pointer) > 0 {
+		location = fmt.Sprintf("%s#%s", location, p.Pointer)
+	}
+	return fmt.Sprintf("%s: failed to %s schema: %s", location, p.Stage, p.Cause)
+}
+
+// ConvertError aggregates all the problems found during a conversion
+type ConvertError struct {
+	Problems []Problem
+}
+
+func (e *ConvertError) Error() string {
+	var lines []string
+	for _, p := range e.Problems {
+		lines = append(lines, p.Error())
+	}
+	return fmt.Sprintf("%d problem(s) found:\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+// WriteText writes the problems grouped by file
+func (e *ConvertError) WriteText(w io.Writer) error {
+	files, grouped := e.groupByFile()
+	if _, err := fmt.Fprintf(w, "%d problem(s) found\n", len(e.Problems)); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if _, err := fmt.Fprintf(w, "\n%s\n", file); err != nil {
+			return err
+		}
+		for _, p := range grouped[file] {
+			location := FirstNonEmpty(p.Pointer, "/")
+			if p.Line > 0 {
+				location = fmt.Sprintf("line %d %s", p.Line, location)
+			}
+			if len(p.Keyword) > 0 {
+				location = fmt.Sprintf("%s (%s)", location, p.Keyword)
+			}
+			if _, err := fmt.Fprintf(w, "  [%s] %s: %s\n", p.Stage, location, p.Cause); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the problems as a json array
+func (e *ConvertError) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e.Problems)
+}
+
+func (e *ConvertError) groupByFile() ([]string, map[string][]Problem) {
+	var files []string
+	grouped := map[string][]Problem{}
+	for _, p := range e.Problems {
+		if _, ok := grouped[p.File]; !ok {
+			files = append(files, p.File)
+		}
+		grouped[p.File] = append(grouped[p.File], p)
+	}
+	sort.Strings(files)
+	return files, grouped
+}
+
+// ToProblems converts an error raised while processing the schema file at path into
+// problems, using the validation errors of the compiler to locate the faulty keywords.
+func ToProblems(stage, path string, err error) []Problem {
+	var schemaErr *jsonschema.SchemaError
+	if errors.As(err, &schemaErr) {
+		path = FirstNonEmpty(FileFromURL(schemaErr.SchemaURL), path)
+	}
+
+	var problems []Problem
+	var validationErr *jsonschema.ValidationError
+	if errors.As(err, &validationErr) {
+		for _, leaf := range leaves(validationErr) {
+			problems = append(problems, Problem{
+				Stage:   stage,
+				File:    path,
+				Pointer: leaf.InstanceLocation,
+				Keyword: lastSegment(leaf.KeywordLocation),
+				Cause:   leaf.Message,
+			})
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var offset int64
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+
+	if len(problems) == 0 {
+		problems = append(problems, Problem{Stage: stage, File: path, Cause: err.Error()})
+	}
+
+	content, readErr := afero.ReadFile(AppFS, path)
+	for i := range problems {
+		if readErr != nil {
+			break
+		}
+		if offset > 0 {
+			problems[i].Line = OffsetLine(content, offset)
+		} else if len(problems[i].Pointer) > 0 {
+			problems[i].Line = PointerLine(content, problems[i].Pointer)
+		}
+	}
+	return problems
+}
+
+// FileFromURL returns the file path of a file:// url, or the url itself otherwise
+func FileFromURL(location string) string {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme != "file" {
+		return location
+	}
+	return u.Path
+}
+
+// leaves returns the validation errors without causes
+func leaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+
+	var result []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		result = append(result, leaves(cause)...)
+	}
+	return result
+}
+
+func lastSegment(pointer string) string {
+	i := strings.LastIndex(pointer, "/")
+	return unescapePointer(pointer[i+1:])
+}
+
+func unescapePointer(token string) string {
+	token = strings.ReplaceAll(token, "~1", "/")
+	return strings.ReplaceAll(token, "~0", "~")
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// OffsetLine returns the 1-based line of the byte offset in content
+func OffsetLine(content []byte, offset int64) int {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+// PointerLine returns the 1-based line at which the value referenced by the json
+// pointer is declared in content, or 0 if it cannot be found.
+func PointerLine(content []byte, pointer string) int {
+	if pointer == "" || pointer == "/" {
+		return 1
+	}
+
+	var target []string
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		target = append(target, unescapePointer(token))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	line, _ := findPointer(dec, content, nil, target)
+	return line
+}
+
+// findPointer reads the next value from dec and returns the line of target within it
+func findPointer(dec *json.Decoder, content []byte, path, target []string) (int, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok || (delim != '{' && delim != '[') {
+		return 0, nil
+	}
+
+	for i := 0; dec.More(); i++ {
+		key := strconv.Itoa(i)
+		if delim == '{' {
+			if tok, err = dec.Token(); err != nil {
+				return 0, err
+			}
+			key = fmt.Sprint(tok)
+		}
+
+		next := append(append([]string{}, path...), key)
+		if equalPath(next, target) {
+			return OffsetLine(content, nextValueOffset(content, dec.InputOffset(), delim == '[')), nil
+		}
+
+		line, err := findPointer(dec, content, next, target)
+		if err != nil || line > 0 {
+			return line, err
+		}
+	}
+
+	_, err = dec.Token()
+	return 0, err
+}
+
+// nextValueOffset skips the whitespace, and separators for array items, after offset
+func nextValueOffset(content []byte, offset int64, array bool) int64 {
+	if !array {
+		return offset
+	}
+	for offset < int64(len(content)) && strings.ContainsRune(" \t\r\n,", rune(content[offset])) {
+		offset++
+	}
+	return offset
+}
+
+func equalPath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointerLine(t *testing.T) {
+	content := []byte(`{
+  "type": "object",
+  "properties": {
+    "a/b": {
+      "type": 5
+    },
+    "list": [
+      1,
+      {"x": true}
+    ]
+  }
+}`)
+
+	testCases := map[string]int{
+		"":                      1,
+		"/type":                 2,
+		"/properties/a~1b":      4,
+		"/properties/a~1b/type": 5,
+		"/properties/list/1":    9,
+		"/properties/list/1/x":  9,
+		"/missing":              0,
+	}
+
+	for pointer, expected := range testCases {
+		actual := PointerLine(content, pointer)
+		assert.Equal(t, expected, actual, pointer)
+	}
+}
+
+func TestToProblems(t *testing.T) {
+	path := "/to-problems/broken.schema.json"
+	writeSchema(t, path, "{\n  \"type\": 5\n}")
+
+	c := NewConverter(config)
+	assert.Nil(t, c.loadSchema(path))
+	_, err := c.compileSchemas([]string{path})
+	assert.IsType(t, &ConvertError{}, err)
+
+	problems := err.(*ConvertError).Problems
+	assert.NotEmpty(t, problems)
+	var keywords []string
+	for _, p := range problems {
+		assert.Equal(t, StageCompile, p.Stage)
+		assert.Equal(t, path, p.File)
+		assert.Equal(t, "/type", p.Pointer)
+		assert.Equal(t, 2, p.Line)
+		keywords = append(keywords, p.Keyword)
+	}
+	// the keywords of the meta schema the value of type fails
+	assert.ElementsMatch(t, []string{"enum", "type"}, keywords)
+}
+
+func TestConvertError_Write(t *testing.T) {
+	err := &ConvertError{Problems: []Problem{
+		{Stage: StageLoad, File: "b.json", Line: 3, Cause: "invalid"},
+		{Stage: StageCompile, File: "a.json", Pointer: "/type", Keyword: "type", Cause: "invalid type"},
+	}}
+
+	var text bytes.Buffer
+	assert.Nil(t, err.WriteText(&text))
+	assert.Equal(t, "2 problem(s) found\n\na.json\n  [compile] /type (type): invalid type\n\nb.json\n  [load] line 3 /: invalid\n", text.String())
+
+	var out bytes.Buffer
+	assert.Nil(t, err.WriteJSON(&out))
+	var problems []Problem
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &problems))
+	assert.Equal(t, err.Problems, problems)
+}
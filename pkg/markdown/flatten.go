@@ -0,0 +1,335 @@
+package markdown
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// branch is a schema merged into a flattened allOf composition
+type branch struct {
+	name   string
+	schema *jsonschema.Schema
+}
+
+// flattener merges the allOf branches of the schemas of a page into their properties,
+// copying the schemas it changes so the compiled schemas are left untouched
+type flattener struct {
+	done map[*jsonschema.Schema]*jsonschema.Schema
+}
+
+// Flatten returns a copy of the schema in which every allOf composition is merged into a
+// single set of properties. Each merged property is annotated with the branches declaring
+// it, the required lists are combined, the restrictions of a property declared by several
+// branches are intersected, and contradictory constraints, as well as differing patterns
+// which a single property cannot show, are flagged.
+func Flatten(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	f := &flattener{done: map[*jsonschema.Schema]*jsonschema.Schema{}}
+	return ToSchema(f.schema(s.Schema), s.Path)
+}
+
+func (f *flattener) schema(s *jsonschema.Schema) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+	if done, ok := f.done[s]; ok {
+		return done
+	}
+
+	c := *s
+	f.done[s] = &c
+	if len(s.AllOf) > 0 {
+		f.merge(&c, s)
+	}
+
+	if c.Properties != nil {
+		properties := make(map[string]*jsonschema.Schema, len(c.Properties))
+		for name, p := range c.Properties {
+			properties[name] = f.schema(p)
+		}
+		c.Properties = properties
+	}
+	if items, ok := c.Items.(*jsonschema.Schema); ok {
+		c.Items = f.schema(items)
+	}
+	c.Items2020 = f.schema(c.Items2020)
+	if additional := AsSchema(c.AdditionalProperties); additional != nil {
+		c.AdditionalProperties = f.schema(additional)
+	}
+	return &c
+}
+
+// merge merges the branches of the allOf composition of s into c
+func (f *flattener) merge(c, s *jsonschema.Schema) {
+	branches := branches(s, map[*jsonschema.Schema]bool{})
+	c.AllOf = nil
+
+	var conflicts []string
+	declared := map[string][]branch{}
+	var names []string
+	var required []string
+	for _, b := range branches {
+		for name, p := range b.schema.Properties {
+			if _, ok := declared[name]; !ok {
+				names = append(names, name)
+			}
+			declared[name] = append(declared[name], branch{b.name, p})
+		}
+		required = appendUnique(required, b.schema.Required...)
+
+		if len(b.schema.Types) > 0 {
+			if len(c.Types) == 0 {
+				c.Types = b.schema.Types
+			} else if c.Types = intersectTypes(c.Types, b.schema.Types); len(c.Types) == 0 {
+				conflicts = append(conflicts, fmt.Sprintf("the type of %s has nothing in common with the other branches", b.name))
+			}
+		}
+	}
+
+	// a branch which disallows additional properties forbids those declared by the others
+	for _, b := range branches {
+		if Allows(AdditionalProperties(b.schema)) != "false" {
+			continue
+		}
+		for _, name := range names {
+			if _, ok := b.schema.Properties[name]; !ok {
+				conflicts = append(conflicts, fmt.Sprintf("%s disallows additional properties, which forbids %s", b.name, name))
+			}
+		}
+	}
+
+	sort.Strings(names)
+	c.Properties = map[string]*jsonschema.Schema{}
+	for _, name := range names {
+		c.Properties[name] = f.property(declared[name])
+	}
+	c.Required = required
+
+	if len(conflicts) > 0 {
+		c.Description = annotate(c.Description, nil, conflicts)
+	}
+}
+
+// property intersects the declarations of a property by several branches
+func (f *flattener) property(declarations []branch) *jsonschema.Schema {
+	merged := *f.schema(declarations[0].schema)
+	sources := []string{declarations[0].name}
+
+	var conflicts []string
+	for _, d := range declarations[1:] {
+		sources = appendUnique(sources, d.name)
+		conflicts = append(conflicts, intersect(&merged, f.schema(d.schema))...)
+	}
+
+	merged.Description = annotate(merged.Description, sources, conflicts)
+	return &merged
+}
+
+// branches returns the schemas merged by the allOf composition of s: s itself when it
+// declares properties, and each branch, following references and nested compositions
+func branches(s *jsonschema.Schema, visited map[*jsonschema.Schema]bool) []branch {
+	var result []branch
+	if visited[s] {
+		return result
+	}
+	visited[s] = true
+
+	if len(s.Properties) > 0 || len(s.Required) > 0 {
+		result = append(result, branch{FirstNonEmpty(s.Title, Humanize(s.Location)), s})
+	}
+
+	for i, b := range s.AllOf {
+		name := fmt.Sprintf("allOf[%d]", i)
+		for b.Ref != nil && len(b.Properties) == 0 {
+			b = b.Ref
+			name = FirstNonEmpty(b.Title, Humanize(b.Location))
+		}
+		if visited[b] {
+			continue
+		}
+
+		if len(b.AllOf) > 0 {
+			result = append(result, branches(b, visited)...)
+			continue
+		}
+		visited[b] = true
+		result = append(result, branch{FirstNonEmpty(b.Title, name), b})
+	}
+	return result
+}
+
+// intersect narrows the restrictions of m to those also allowed by s, and describes the
+// constraints which contradict each other
+func intersect(m, s *jsonschema.Schema) []string {
+	var conflicts []string
+	conflict := func(format string, args ...interface{}) {
+		conflicts = append(conflicts, fmt.Sprintf(format, args...))
+	}
+
+	if len(m.Types) > 0 && len(s.Types) > 0 {
+		types := intersectTypes(m.Types, s.Types)
+		if len(types) == 0 {
+			conflict("types %s and %s have nothing in common", strings.Join(m.Types, ", "), strings.Join(s.Types, ", "))
+		}
+		m.Types = types
+	} else if len(m.Types) == 0 {
+		m.Types = s.Types
+	}
+
+	m.Minimum = maxRat(m.Minimum, s.Minimum)
+	m.ExclusiveMinimum = maxRat(m.ExclusiveMinimum, s.ExclusiveMinimum)
+	m.Maximum = minRat(m.Maximum, s.Maximum)
+	m.ExclusiveMaximum = minRat(m.ExclusiveMaximum, s.ExclusiveMaximum)
+	if m.Minimum != nil && m.Maximum != nil && m.Minimum.Cmp(m.Maximum) > 0 {
+		conflict("minimum %s is greater than maximum %s", m.Minimum.RatString(), m.Maximum.RatString())
+	}
+
+	m.MinLength, m.MaxLength = maxInt(m.MinLength, s.MinLength), minLimit(m.MaxLength, s.MaxLength)
+	if m.MaxLength >= 0 && m.MinLength > m.MaxLength {
+		conflict("min length %d is greater than max length %d", m.MinLength, m.MaxLength)
+	}
+
+	m.MinItems, m.MaxItems = maxInt(m.MinItems, s.MinItems), minLimit(m.MaxItems, s.MaxItems)
+	if m.MaxItems >= 0 && m.MinItems > m.MaxItems {
+		conflict("min items %d is greater than max items %d", m.MinItems, m.MaxItems)
+	}
+
+	switch {
+	case m.Enum == nil:
+		m.Enum = s.Enum
+	case s.Enum != nil:
+		var enum []interface{}
+		for _, v := range m.Enum {
+			if contains(s.Enum, v) {
+				enum = append(enum, v)
+			}
+		}
+		if len(enum) == 0 {
+			conflict("enums %v and %v have no value in common", m.Enum, s.Enum)
+		}
+		m.Enum = enum
+	}
+
+	switch {
+	case len(m.Constant) == 0:
+		m.Constant = s.Constant
+	case len(s.Constant) > 0 && !reflect.DeepEqual(m.Constant[0], s.Constant[0]):
+		conflict("constants %v and %v differ", m.Constant[0], s.Constant[0])
+	}
+
+	switch {
+	case len(m.Format) == 0:
+		m.Format = s.Format
+	case len(s.Format) > 0 && m.Format != s.Format:
+		conflict("formats %s and %s differ", m.Format, s.Format)
+	}
+
+	switch {
+	case m.Pattern == nil:
+		m.Pattern = s.Pattern
+	case s.Pattern != nil && m.Pattern.String() != s.Pattern.String():
+		// the table documents a single pattern while the value must match both
+		first, _ := samplePattern(m)
+		second, _ := samplePattern(s)
+		conflict("patterns %s and %s differ, the value must match both", first, second)
+	}
+	if m.Ref == nil {
+		m.Ref = s.Ref
+	}
+	m.Description = FirstNonEmpty(m.Description, s.Description)
+	m.Required = appendUnique(m.Required, s.Required...)
+	if len(s.Properties) > 0 {
+		properties := map[string]*jsonschema.Schema{}
+		for name, p := range s.Properties {
+			properties[name] = p
+		}
+		for name, p := range m.Properties {
+			properties[name] = p
+		}
+		m.Properties = properties
+	}
+	return conflicts
+}
+
+// annotate appends the branches a property comes from and its conflicts to its description
+func annotate(description string, sources, conflicts []string) string {
+	var lines []string
+	if len(description) > 0 {
+		lines = append(lines, description)
+	}
+	if len(sources) > 0 {
+		lines = append(lines, fmt.Sprintf("_From: %s_", strings.Join(sources, ", ")))
+	}
+	for _, c := range conflicts {
+		lines = append(lines, fmt.Sprintf("**Conflict:** %s", c))
+	}
+	return strings.Join(lines, "<br>")
+}
+
+// intersectTypes returns the types allowed by both lists, an integer being a number
+func intersectTypes(a, b []string) []string {
+	var types []string
+	for _, t := range a {
+		switch {
+		case IndexOf(b, t) > 0:
+			types = appendUnique(types, t)
+		case t == "integer" && IndexOf(b, "number") > 0, t == "number" && IndexOf(b, "integer") > 0:
+			types = appendUnique(types, "integer")
+		}
+	}
+	return types
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if IndexOf(list, v) < 0 {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if reflect.DeepEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func maxRat(a, b *big.Rat) *big.Rat {
+	if a == nil || (b != nil && b.Cmp(a) > 0) {
+		return b
+	}
+	return a
+}
+
+func minRat(a, b *big.Rat) *big.Rat {
+	if a == nil || (b != nil && b.Cmp(a) < 0) {
+		return b
+	}
+	return a
+}
+
+func maxInt(a, b int) int {
+	if b > a {
+		return b
+	}
+	return a
+}
+
+// minLimit returns the lowest of two limits, where -1 means unlimited
+func minLimit(a, b int) int {
+	if a < 0 || (b >= 0 && b < a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,113 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const allOfSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Order",
+  "type": "object",
+  "$defs": {
+    "base": {
+      "title": "Base",
+      "type": "object",
+      "properties": {
+        "id": {"type": "string", "description": "The identifier"},
+        "quantity": {"type": "integer", "minimum": 0, "maximum": 100},
+        "status": {"enum": ["open", "closed"]}
+      },
+      "required": ["id"]
+    }
+  },
+  "allOf": [
+    {"$ref": "#/$defs/base"},
+    {
+      "properties": {
+        "quantity": {"type": "number", "minimum": 10},
+        "status": {"enum": ["pending"]},
+        "note": {"type": "string"}
+      },
+      "required": ["quantity"]
+    }
+  ]
+}`
+
+func TestFlatten(t *testing.T) {
+	writeSchema(t, "/flatten/order.schema.json", allOfSchema)
+	c := NewConverter(config)
+	schemas, err := c.Load("/flatten")
+	assert.Nil(t, err)
+
+	flat := Flatten(schemas[0])
+	assert.Empty(t, flat.AllOf)
+	assert.NotEmpty(t, schemas[0].AllOf)
+	assert.Equal(t, []string{"id", "quantity"}, flat.Required)
+	assert.Len(t, flat.Properties, 4)
+
+	quantity := flat.Properties["quantity"]
+	assert.Equal(t, []string{"integer"}, quantity.Types)
+	assert.Equal(t, "10", quantity.Minimum.RatString())
+	assert.Equal(t, "100", quantity.Maximum.RatString())
+	assert.Equal(t, "_From: Base, allOf[1]_", quantity.Description)
+
+	assert.Equal(t, "The identifier<br>_From: Base_", flat.Properties["id"].Description)
+	assert.Contains(t, flat.Properties["status"].Description, "**Conflict:** enums [open closed] and [pending] have no value in common")
+}
+
+func TestFlattenPatterns(t *testing.T) {
+	writeSchema(t, "/flatten-patterns/code.schema.json", `{
+  "type": "object",
+  "allOf": [
+    {"properties": {"code": {"type": "string", "pattern": "^[a-z]+$"}}},
+    {"properties": {"code": {"type": "string", "pattern": "^.{3}$"}}},
+    {"properties": {"code": {"type": "string", "pattern": "^[a-z]+$"}}}
+  ]
+}`)
+	schemas, err := NewConverter(config).Load("/flatten-patterns")
+	assert.Nil(t, err)
+
+	code := Flatten(schemas[0]).Properties["code"]
+	assert.Equal(t, "^[a-z]+$", code.Pattern.String())
+	assert.Equal(t, "_From: allOf[0], allOf[1], allOf[2]_<br>**Conflict:** patterns ^[a-z]+$ and ^.{3}$ differ, the value must match both", code.Description)
+}
+
+func TestConverter_ConvertFlattenAllOf(t *testing.T) {
+	writeSchema(t, "/flatten-allof/order.schema.json", allOfSchema)
+	cfg := config
+	cfg.Destination = "/flatten-allof-output"
+	cfg.FlattenAllOf = true
+	assert.Nil(t, NewConverter(cfg).Convert("/flatten-allof"))
+
+	page := readTree(t, cfg.Destination)["order-schema/_index.md"]
+	assert.Contains(t, page, "| id | String | The identifier<br>_From: Base_ |")
+	assert.Contains(t, page, "| note | String | _From: allOf[1]_ |")
+	assert.Contains(t, page, "| quantity | Integer | _From: Base, allOf[1]_ |")
+	assert.NotContains(t, page, "AllOf")
+}
+
+func TestIntersect(t *testing.T) {
+	testCases := map[string]struct {
+		a, b     string
+		conflict string
+	}{
+		"types":     {`{"type": "string"}`, `{"type": "integer"}`, "types string and integer have nothing in common"},
+		"bounds":    {`{"minimum": 5}`, `{"maximum": 3}`, "minimum 5 is greater than maximum 3"},
+		"length":    {`{"minLength": 5}`, `{"maxLength": 3}`, "min length 5 is greater than max length 3"},
+		"items":     {`{"minItems": 2}`, `{"maxItems": 1}`, "min items 2 is greater than max items 1"},
+		"constants": {`{"const": "a"}`, `{"const": "b"}`, "constants a and b differ"},
+		"formats":   {`{"format": "date"}`, `{"format": "email"}`, "formats date and email differ"},
+	}
+
+	for name, tc := range testCases {
+		a, b := compileString(t, tc.a), compileString(t, tc.b)
+		assert.Equal(t, []string{tc.conflict}, intersect(a, b), name)
+	}
+
+	a, b := compileString(t, `{"type": ["string", "null"], "maxLength": 10}`), compileString(t, `{"type": "string", "maxLength": 5}`)
+	assert.Empty(t, intersect(a, b))
+	assert.Equal(t, []string{"string"}, a.Types)
+	assert.Equal(t, 5, a.MaxLength)
+}
@@ -0,0 +1,216 @@
+package markdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// The formats in which the front matter of the generated pages can be written
+const (
+	FrontMatterYAML = "yaml"
+	FrontMatterTOML = "toml"
+)
+
+// FrontMatter configures the front matter of the generated schema and section index pages
+type FrontMatter struct {
+	// Format is the format of the front matter, yaml or toml, defaults to yaml
+	Format string `json:"format" yaml:"format"`
+
+	// Description adds the description of the schema to its page
+	Description bool `json:"description" yaml:"description"`
+
+	// Extensions are the x- keys of a schema which are added to its page, without their x- prefix
+	Extensions []string `json:"extensions" yaml:"extensions"`
+
+	// Pages are the keys added to every schema page
+	Pages map[string]interface{} `json:"pages" yaml:"pages"`
+
+	// Indexes are the keys added to every section index page
+	Indexes map[string]interface{} `json:"indexes" yaml:"indexes"`
+}
+
+// Field is a single key of the front matter
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// LoadFrontMatter reads the front matter configuration from the yaml file at path
+func LoadFrontMatter(path string) (FrontMatter, error) {
+	var f FrontMatter
+	b, err := afero.ReadFile(AppFS, path)
+	if err != nil {
+		return f, errors.Wrapf(err, "failed to read front matter: %s", path)
+	}
+	if err = yaml.Unmarshal(b, &f); err != nil {
+		return f, errors.Wrapf(err, "failed to decode front matter: %s", path)
+	}
+	return f, nil
+}
+
+// Validate checks the format of the front matter
+func (f FrontMatter) Validate() error {
+	switch f.Format {
+	case "", FrontMatterYAML, FrontMatterTOML:
+		return nil
+	default:
+		return fmt.Errorf(`invalid front matter format "%s", expected %s or %s`, f.Format, FrontMatterYAML, FrontMatterTOML)
+	}
+}
+
+// PageFields returns the fields of the page of a schema: its title and weight, followed by
+// its description and extensions when enabled, and the configured page keys.
+func (f FrontMatter) PageFields(title string, weight int, description string, raw RawSchema) []Field {
+	fields := []Field{{"title", title}}
+	if weight > 0 {
+		fields = append(fields, Field{"weight", weight})
+	}
+	if f.Description && len(description) > 0 {
+		fields = append(fields, Field{"description", description})
+	}
+	for _, key := range f.Extensions {
+		if value, ok := raw[key]; ok {
+			fields = append(fields, Field{strings.TrimPrefix(key, "x-"), value})
+		}
+	}
+	return appendFields(fields, f.Pages)
+}
+
+// IndexFields returns the fields of a section index page: its title followed by the
+// configured index keys.
+func (f FrontMatter) IndexFields(title string) []Field {
+	return appendFields([]Field{{"title", title}}, f.Indexes)
+}
+
+// Render writes the fields, including the delimiters, in the configured format
+func (f FrontMatter) Render(fields []Field) (string, error) {
+	if err := f.Validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	delimiter := "---"
+	if f.Format == FrontMatterTOML {
+		delimiter = "+++"
+	}
+	b.WriteString(delimiter + "\n")
+	for _, field := range fields {
+		line, err := f.render(field)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to write front matter key: %s", field.Key)
+		}
+		b.WriteString(line)
+	}
+	b.WriteString(delimiter)
+	return b.String(), nil
+}
+
+func (f FrontMatter) render(field Field) (string, error) {
+	if f.Format == FrontMatterTOML {
+		value, err := tomlValue(field.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s = %s\n", tomlKey(field.Key), value), nil
+	}
+
+	b, err := yaml.Marshal(map[string]interface{}{field.Key: field.Value})
+	return string(b), err
+}
+
+// appendFields appends the keys of values, alphabetically, which are not already in fields
+func appendFields(fields []Field, values map[string]interface{}) []Field {
+	set := map[string]bool{}
+	for _, field := range fields {
+		set[field.Key] = true
+	}
+
+	var keys []string
+	for key := range values {
+		if !set[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fields = append(fields, Field{key, values[key]})
+	}
+	return fields
+}
+
+var bareKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(key string) string {
+	if bareKeyRe.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlString quotes s as a basic string, the json escapes are valid toml escapes
+func tomlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// tomlValue writes the value inline, nested maps are written as inline tables
+func tomlValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", errors.New("toml has no null value")
+	case string:
+		return tomlString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		var items []string
+		for i := 0; i < rv.Len(); i++ {
+			item, err := tomlValue(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		var items []string
+		for _, k := range keys {
+			item, err := tomlValue(rv.MapIndex(k).Interface())
+			if err != nil {
+				return "", err
+			}
+			items = append(items, fmt.Sprintf("%s = %s", tomlKey(fmt.Sprint(k.Interface())), item))
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	}
+	return "", fmt.Errorf("unsupported value: %v", value)
+}
@@ -0,0 +1,76 @@
+package markdown
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrontMatter_Render(t *testing.T) {
+	f := FrontMatter{
+		Description: true,
+		Extensions:  []string{"x-tags"},
+		Pages: map[string]interface{}{
+			"draft": false,
+			"title": "ignored",
+			"menu":  map[string]interface{}{"main": map[string]interface{}{"parent": "reference"}},
+		},
+	}
+	raw := RawSchema{"x-tags": []interface{}{"geo", "location"}, "x-other": "ignored"}
+	fields := f.PageFields("Product", 2, "A product", raw)
+
+	actual, err := f.Render(fields)
+	assert.Nil(t, err)
+	assert.Equal(t, `---
+title: Product
+weight: 2
+description: A product
+tags:
+    - geo
+    - location
+draft: false
+menu:
+    main:
+        parent: reference
+---`, actual)
+
+	f.Format = FrontMatterTOML
+	actual, err = f.Render(fields)
+	assert.Nil(t, err)
+	assert.Equal(t, `+++
+title = "Product"
+weight = 2
+description = "A product"
+tags = ["geo", "location"]
+draft = false
+menu = { main = { parent = "reference" } }
++++`, actual)
+}
+
+func TestFrontMatter_Validate(t *testing.T) {
+	assert.Nil(t, FrontMatter{}.Validate())
+	assert.Nil(t, FrontMatter{Format: FrontMatterTOML}.Validate())
+	assert.NotNil(t, FrontMatter{Format: "json"}.Validate())
+}
+
+func TestLoadFrontMatter(t *testing.T) {
+	writeSchema(t, "/front-matter.yaml", "format: toml\nindexes:\n  draft: true\n")
+	f, err := LoadFrontMatter("/front-matter.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, FrontMatterTOML, f.Format)
+	assert.Equal(t, map[string]interface{}{"draft": true}, f.Indexes)
+}
+
+func TestConverter_createIndexFrontMatter(t *testing.T) {
+	cfg := config
+	cfg.Destination = "/front-matter"
+	cfg.FrontMatter = FrontMatter{Format: FrontMatterTOML, Indexes: map[string]interface{}{"draft": true}}
+	c := NewConverter(cfg)
+	assert.Nil(t, c.createIndex(filepath.Join(cfg.Destination, "section")))
+
+	b, err := afero.ReadFile(AppFS, filepath.Join(cfg.Destination, "section", "_index.md"))
+	assert.Nil(t, err)
+	assert.Equal(t, "+++\ntitle = \"section\"\ndraft = true\n+++", string(b))
+}
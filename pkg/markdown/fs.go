@@ -10,6 +10,11 @@ import (
 var AppFS = afero.NewOsFs()
 
 func FindFiles(path string, pattern string, recursive bool) ([]string, error) {
+	// a single file, e.g. an OpenAPI document, is loaded whatever its extension
+	if info, err := AppFS.Stat(path); err == nil && !info.IsDir() {
+		return []string{path}, nil
+	}
+
 	if !recursive {
 		return filterFiles(path, pattern)
 	}
@@ -35,3 +40,11 @@ func filterFiles(path, filter string) ([]string, error) {
 	pattern := fmt.Sprintf("%s/%s", path, filter)
 	return afero.Glob(AppFS, pattern)
 }
+
+// TemplateOverrides returns the gohtml templates in dir, none when dir is empty
+func TemplateOverrides(dir string) ([]string, error) {
+	if len(dir) == 0 {
+		return nil, nil
+	}
+	return filterFiles(dir, "*.gohtml")
+}
@@ -0,0 +1,123 @@
+package markdown
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Index is the data the index.gohtml template renders a section index page from
+type Index struct {
+	// Title is the name of the section directory
+	Title string
+
+	// Schema is the schema the section belongs to, it is nil for the top-level catalogue
+	Schema *Schema
+
+	// Schemas are the schemas with a page in the section, or every root schema for the
+	// top-level catalogue
+	Schemas []*Schema
+
+	// Catalogue is set for the index of the destination directory
+	Catalogue bool
+}
+
+// renderIndexes renders the index of each section directory created for the pages, and
+// a catalogue of the root schemas in the destination directory. Indexes which were not
+// generated by the converter, and directories whose index is a schema page, are skipped.
+func (c *Converter) renderIndexes(pages []page) error {
+	if c.config.SinglePage {
+		return nil
+	}
+
+	sections := map[string]*Schema{}
+	contents := map[string][]*Schema{}
+	catalogue := &Index{Title: filepath.Base(filepath.Clean(c.config.Destination)), Catalogue: true}
+	for _, p := range pages {
+		dir := filepath.Clean(c.pageDir(p.schema))
+		if p.name == "_index" {
+			sections[dir] = p.schema
+			catalogue.Schemas = append(catalogue.Schemas, p.schema)
+			continue
+		}
+		contents[dir] = append(contents[dir], p.schema)
+	}
+
+	c.mu.RLock()
+	var dirs []string
+	for dir := range c.indexes {
+		if _, ok := sections[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	c.mu.RUnlock()
+	sort.Strings(dirs)
+
+	c.sortSchemas(catalogue.Schemas)
+	for _, dir := range dirs {
+		c.sortSchemas(contents[dir])
+		index := &Index{Title: filepath.Base(dir), Schema: c.section(dir, sections), Schemas: contents[dir]}
+		if err := c.renderIndex(filepath.Join(dir, "_index.md"), index); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(c.config.Destination, "_index.md")
+	if PathExist(path) && !c.generatedBefore(path) {
+		log.Infof("keeping the existing catalogue, it was not generated: %s", path)
+		return nil
+	}
+	c.track(path)
+	return c.renderIndex(path, catalogue)
+}
+
+// sortSchemas sorts the schemas by their position in their file when the schema order is
+// preserved, and alphabetically by title otherwise
+func (c *Converter) sortSchemas(schemas []*Schema) {
+	weight := GetWeight(c.order)
+	sort.SliceStable(schemas, func(i, j int) bool {
+		a, b := schemas[i], schemas[j]
+		if c.config.Ordered || c.config.OrderedFilePath {
+			if wa, wb := weight(a.Path, a.Location), weight(b.Path, b.Location); wa != wb {
+				return wa < wb
+			}
+		}
+		return strings.ToLower(FirstNonEmpty(a.Title, Humanize(a.Location))) < strings.ToLower(FirstNonEmpty(b.Title, Humanize(b.Location)))
+	})
+}
+
+// section returns the schema whose page is the index of dir or of its closest parent
+func (c *Converter) section(dir string, sections map[string]*Schema) *Schema {
+	root := filepath.Clean(c.config.Destination)
+	for ; len(dir) > len(root); dir = filepath.Dir(dir) {
+		if s, ok := sections[dir]; ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// renderIndex renders the index at path if it was generated by the converter
+func (c *Converter) renderIndex(path string, index *Index) error {
+	rel, err := filepath.Rel(c.config.Destination, path)
+	if err != nil || !c.manifest.Has(filepath.ToSlash(rel)) {
+		return nil
+	}
+
+	tmpl, err := c.pageTemplate(path)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("rendering index: %s", path)
+	var buf bytes.Buffer
+	if err = tmpl.ExecuteTemplate(&buf, "index", index); err != nil {
+		return errors.Wrapf(err, "failed to render index: %s", path)
+	}
+	_, err = WriteIfChanged(path, buf.Bytes())
+	return err
+}
@@ -0,0 +1,88 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const orderSchema = `{
+  "title": "Order",
+  "description": "A customer order.\nPlaced online.",
+  "type": "object",
+  "properties": {
+    "customer": {"$ref": "#/definitions/customer"},
+    "items": {"type": "array", "items": {"$ref": "#/definitions/item"}}
+  },
+  "definitions": {
+    "customer": {"title": "Customer", "description": "The buyer | owner", "type": "object"},
+    "item": {"title": "Item", "description": "A line item", "type": "object"}
+  }
+}`
+
+func TestConverter_renderIndexes(t *testing.T) {
+	writeSchema(t, "/indexes/order.schema.json", orderSchema)
+	writeSchema(t, "/indexes-output/reference/notes/_index.md", "hand written")
+
+	cfg := config
+	cfg.Destination = "/indexes-output/reference"
+	assert.Nil(t, NewConverter(cfg).Convert("/indexes"))
+
+	files := readTree(t, cfg.Destination)
+	assert.Equal(t, `---
+title: definitions
+---
+**Order**
+
+A customer order.
+Placed online.
+
+| Definition | Description |
+|--------|-------------|
+| [Customer]({{%baseurl%}}/reference/order-schema/definitions/#customer) | The buyer \| owner |
+| [Item]({{%baseurl%}}/reference/order-schema/definitions/#item) | A line item |
+`, files["order-schema/definitions/_index.md"])
+
+	assert.Equal(t, `---
+title: reference
+---
+| Schema | Description |
+|--------|-------------|
+| [Order]({{%baseurl%}}/reference/order-schema/#order) | A customer order. |
+`, files["_index.md"])
+	assert.Equal(t, "hand written", files["notes/_index.md"])
+}
+
+func TestConverter_renderIndexesExistingCatalogue(t *testing.T) {
+	writeSchema(t, "/catalogue/order.schema.json", orderSchema)
+	writeSchema(t, "/catalogue-output/_index.md", "hand written")
+
+	cfg := config
+	cfg.Destination = "/catalogue-output"
+	assert.Nil(t, NewConverter(cfg).Convert("/catalogue"))
+	assert.Nil(t, NewConverter(cfg).Convert("/catalogue"))
+
+	files := readTree(t, cfg.Destination)
+	assert.Equal(t, "hand written", files["_index.md"])
+	assert.Contains(t, files, "order-schema/definitions/_index.md")
+}
+
+func TestConverter_templateOverrides(t *testing.T) {
+	writeSchema(t, "/overrides/order.schema.json", orderSchema)
+	writeSchema(t, "/overrides-templates/index.gohtml", `{{ define "index" }}{{ len .Schemas }} schemas{{ end }}`)
+
+	cfg := config
+	cfg.Destination = "/overrides-output"
+	cfg.Templates = "/overrides-templates"
+	assert.Nil(t, NewConverter(cfg).Convert("/overrides"))
+
+	files := readTree(t, cfg.Destination)
+	assert.Equal(t, "2 schemas", files["order-schema/definitions/_index.md"])
+	assert.Equal(t, "1 schemas", files["_index.md"])
+}
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "first line", Summary(" first line\nsecond line"))
+	assert.Equal(t, `a \| b`, Summary("a | b"))
+	assert.Equal(t, "", Summary(""))
+}
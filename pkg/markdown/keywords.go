@@ -0,0 +1,163 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Keyword is a custom keyword of a schema, rendered in the restrictions column by the
+// template named keyword-<name> when defined, or the keyword template otherwise
+type Keyword struct {
+	// Name is the keyword, e.g. x-unit
+	Name string
+
+	// Value is the raw json value of the keyword
+	Value interface{}
+
+	// Text is the value as compact json, strings being unquoted
+	Text string
+
+	// Schema is the schema the keyword belongs to
+	Schema *jsonschema.Schema
+}
+
+// builtinFormats are the formats validated by the jsonschema package itself, which custom
+// formats cannot replace
+var builtinFormats = formatNames()
+
+func formatNames() map[string]bool {
+	names := map[string]bool{}
+	for name := range jsonschema.Formats {
+		names[name] = true
+	}
+	return names
+}
+
+// RegexpFormat returns a format validator accepting the strings matching re. Values which
+// are not strings are accepted, as formats only apply to strings.
+func RegexpFormat(re *regexp.Regexp) func(v interface{}) bool {
+	return func(v interface{}) bool {
+		s, ok := v.(string)
+		return !ok || re.MatchString(s)
+	}
+}
+
+// CompileFormats compiles the regular expressions of the configured formats
+func (c Config) CompileFormats() (map[string]*regexp.Regexp, error) {
+	formats := map[string]*regexp.Regexp{}
+	for name, pattern := range c.Formats {
+		if builtinFormats[name] {
+			return nil, fmt.Errorf(`format "%s" is a built-in format and cannot be replaced`, name)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid pattern of format "%s": %v`, name, err)
+		}
+		formats[name] = re
+	}
+	return formats, nil
+}
+
+// RegisterFormat registers a format validated by fn, e.g. sku, which the schemas compiled
+// by the converter assert whatever their draft. The format is scoped to the converter, the
+// built-in formats such as email keep the behaviour of the draft and cannot be replaced.
+func (c *Converter) RegisterFormat(name string, fn func(v interface{}) bool) error {
+	if builtinFormats[name] {
+		return fmt.Errorf(`format "%s" is a built-in format and cannot be replaced`, name)
+	}
+	c.formats[name] = fn
+	return nil
+}
+
+// formatExtension asserts the formats registered with the converter
+type formatExtension struct {
+	c *Converter
+}
+
+// formatSchema validates a value against a registered format
+type formatSchema struct {
+	name string
+	fn   func(v interface{}) bool
+}
+
+func (e formatExtension) Compile(_ jsonschema.CompilerContext, m map[string]interface{}) (jsonschema.ExtSchema, error) {
+	name, _ := m["format"].(string)
+	if fn, ok := e.c.formats[name]; ok {
+		return formatSchema{name, fn}, nil
+	}
+	return nil, nil
+}
+
+func (s formatSchema) Validate(ctx jsonschema.ValidationContext, v interface{}) error {
+	if !s.fn(v) {
+		return ctx.Error("format", "%q is not valid %s", v, s.name)
+	}
+	return nil
+}
+
+// RegisterExtension registers custom keywords compiled by ext with the compiler, meta being
+// the meta schema validating them. The keywords are rendered in the restrictions column.
+func (c *Converter) RegisterExtension(name string, meta *jsonschema.Schema, ext jsonschema.ExtCompiler, keywords ...string) {
+	c.compiler.RegisterExtension(name, meta, ext)
+	c.keywords = append(c.keywords, keywords...)
+}
+
+// findKeywords returns the custom keywords of the schema, a *Schema or *jsonschema.Schema,
+// alphabetically
+func (c *Converter) findKeywords(v interface{}) []Keyword {
+	var s *jsonschema.Schema
+	switch schema := v.(type) {
+	case *Schema:
+		s = schema.Schema
+	case *jsonschema.Schema:
+		s = schema
+	}
+
+	names := append(append([]string{}, c.config.Keywords...), c.keywords...)
+	if s == nil || len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	raw := c.rawSchema(s.Location)
+	var keywords []Keyword
+	for i, name := range names {
+		value, ok := raw[name]
+		if !ok || (i > 0 && names[i-1] == name) {
+			continue
+		}
+		keywords = append(keywords, Keyword{name, value, keywordText(value), s})
+	}
+	return keywords
+}
+
+// renderKeyword renders the keyword with its own template when defined
+func (c *Converter) renderKeyword(k Keyword) (string, error) {
+	name := "keyword-" + k.Name
+	if c.template.Lookup(name) == nil {
+		name = "keyword"
+	}
+
+	var buf bytes.Buffer
+	if err := c.template.ExecuteTemplate(&buf, name, k); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func keywordText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
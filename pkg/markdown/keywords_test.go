@@ -0,0 +1,124 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const keywordSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Product",
+  "type": "object",
+  "properties": {
+    "sku": {"type": "string", "format": "sku"},
+    "weight": {"type": "number", "x-unit": "kg"},
+    "price": {"type": "number", "x-currency": {"code": "EUR"}, "multipleOfTen": true}
+  }
+}`
+
+// tenExtension compiles the multipleOfTen keyword
+type tenExtension struct{}
+
+func (tenExtension) Compile(_ jsonschema.CompilerContext, m map[string]interface{}) (jsonschema.ExtSchema, error) {
+	if v, ok := m["multipleOfTen"].(bool); ok && v {
+		return tenSchema{}, nil
+	}
+	return nil, nil
+}
+
+type tenSchema struct{}
+
+func (tenSchema) Validate(_ jsonschema.ValidationContext, _ interface{}) error {
+	return nil
+}
+
+func TestRegexpFormat(t *testing.T) {
+	format := RegexpFormat(regexp.MustCompile(`^[A-Z]{3}-[0-9]+$`))
+	assert.True(t, format("ABC-1"))
+	assert.False(t, format("abc"))
+	assert.True(t, format(42))
+}
+
+func TestConfig_CompileFormats(t *testing.T) {
+	formats, err := Config{Formats: map[string]string{"sku": `^[A-Z]+$`}}.CompileFormats()
+	assert.Nil(t, err)
+	assert.True(t, formats["sku"].MatchString("ABC"))
+
+	_, err = Config{Formats: map[string]string{"sku": `[`}}.CompileFormats()
+	assert.NotNil(t, err)
+
+	_, err = Config{Formats: map[string]string{"email": `^.+$`}}.CompileFormats()
+	assert.EqualError(t, err, `format "email" is a built-in format and cannot be replaced`)
+}
+
+func TestConverter_RegisterFormat(t *testing.T) {
+	c := NewConverter(config)
+	assert.NotNil(t, c.RegisterFormat("email", RegexpFormat(regexp.MustCompile(`^.+$`))))
+	assert.False(t, c.compiler.AssertFormat)
+
+	assert.Nil(t, c.RegisterFormat("sku", RegexpFormat(regexp.MustCompile(`^[A-Z]{3}-[0-9]+$`))))
+	assert.False(t, c.compiler.AssertFormat)
+	assert.Nil(t, jsonschema.Formats["sku"])
+}
+
+func TestConverter_ConvertKeywords_Custom(t *testing.T) {
+	writeSchema(t, "/custom/product.schema.json", keywordSchema)
+	writeSchema(t, "/custom-templates/keywords.gohtml", `{{ define "keyword-x-unit" }}**Unit:** {{ .Text }}{{ end }}`)
+
+	cfg := config
+	cfg.Destination = "/custom-output"
+	cfg.Templates = "/custom-templates"
+	cfg.Formats = map[string]string{"sku": `^[A-Z]{3}-[0-9]+$`}
+	cfg.Keywords = []string{"x-unit", "x-currency"}
+	c := NewConverter(cfg)
+	c.RegisterExtension("ten", nil, tenExtension{}, "multipleOfTen")
+	assert.Nil(t, c.Convert("/custom"))
+
+	assert.Nil(t, jsonschema.Formats["sku"])
+
+	page := readTree(t, cfg.Destination)["product-schema/_index.md"]
+	assert.Contains(t, page, "| sku | String |  | **Format:** sku |")
+	assert.Contains(t, page, "| weight | Number |  | **Unit:** kg |")
+	assert.Contains(t, page, `| price | Number |  | **multipleOfTen:** true<br>**x-currency:** {"code":"EUR"} |`)
+
+	cfg.Formats = map[string]string{"sku": `[`}
+	assert.NotNil(t, NewConverter(cfg).Convert("/custom"))
+	_, err := NewConverter(cfg).Load("/custom")
+	assert.NotNil(t, err)
+}
+
+func TestConverter_ConvertKeywords_Custom2020(t *testing.T) {
+	// drafts 2019-09 and later only annotate formats unless they are asserted
+	schema := strings.Replace(keywordSchema, "http://json-schema.org/draft-07/schema#", "https://json-schema.org/draft/2020-12/schema", 1)
+	writeSchema(t, "/custom2020/product.schema.json", schema)
+
+	cfg := config
+	cfg.Destination = "/custom2020-output"
+	cfg.Formats = map[string]string{"sku": `^[A-Z]{3}-[0-9]+$`}
+	c := NewConverter(cfg)
+	assert.Nil(t, c.Convert("/custom2020"))
+
+	s, err := c.compiler.Compile("/custom2020/product.schema.json")
+	assert.Nil(t, err)
+	assert.Nil(t, s.Validate(map[string]interface{}{"sku": "ABC-1"}))
+	assert.NotNil(t, s.Validate(map[string]interface{}{"sku": "abc"}))
+
+	// the built-in formats are still only annotated
+	writeSchema(t, "/custom2020/contact.schema.json", `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {"email": {"type": "string", "format": "email"}}
+}`)
+	contact, err := c.LoadFile("/custom2020/contact.schema.json")
+	assert.Nil(t, err)
+	assert.Nil(t, contact.Validate(map[string]interface{}{"email": "not an email"}))
+
+	// other converters do not know the format
+	product, err := NewConverter(config).LoadFile("/custom2020/product.schema.json")
+	assert.Nil(t, err)
+	assert.Nil(t, product.Validate(map[string]interface{}{"sku": "abc"}))
+}
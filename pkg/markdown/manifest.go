@@ -0,0 +1,151 @@
+package markdown
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// ManifestFile is the name of the manifest kept in the destination directory
+const ManifestFile = ".presidium-json-schema.manifest.json"
+
+// Manifest tracks the files produced by the converter, relative to the destination,
+// so that files which are no longer produced can be removed without touching files
+// the converter does not manage.
+type Manifest struct {
+	files map[string]bool
+	mu    sync.Mutex
+}
+
+type manifestFile struct {
+	Files []string `json:"files"`
+}
+
+func NewManifest() *Manifest {
+	return &Manifest{files: map[string]bool{}}
+}
+
+// LoadManifest reads the manifest from path, an empty manifest is returned if it does not exist
+func LoadManifest(path string) (*Manifest, error) {
+	m := NewManifest()
+	b, err := afero.ReadFile(AppFS, path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest: %s", path)
+	}
+
+	var file manifestFile
+	if err = json.Unmarshal(b, &file); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode manifest: %s", path)
+	}
+	for _, f := range file.Files {
+		m.files[f] = true
+	}
+	return m, nil
+}
+
+// Add records a produced file
+func (m *Manifest) Add(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[path] = true
+}
+
+// Has reports whether the file was produced
+func (m *Manifest) Has(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files[path]
+}
+
+// Files returns the produced files in alphabetical order
+func (m *Manifest) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var files []string
+	for f := range m.files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Save writes the manifest to path
+func (m *Manifest) Save(path string) error {
+	b, err := json.MarshalIndent(manifestFile{Files: m.Files()}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode manifest")
+	}
+	if err = AppFS.MkdirAll(filepath.Dir(path), fs.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to create manifest directory: %s", path)
+	}
+	return afero.WriteFile(AppFS, path, b, os.ModePerm)
+}
+
+// Prune removes the files of the previous manifest, relative to root, that are no longer
+// produced, along with any directories left empty by their removal. Entries that are absolute
+// or lead outside root are left alone.
+func (m *Manifest) Prune(previous *Manifest, root string) error {
+	for _, f := range previous.Files() {
+		if m.Has(f) {
+			continue
+		}
+
+		path, ok := within(root, f)
+		if !ok {
+			log.Warnf("ignoring manifest entry outside the destination: %s", f)
+			continue
+		}
+		log.Infof("removing stale file: %s", path)
+		if err := AppFS.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove stale file: %s", path)
+		}
+
+		if err := removeEmptyDirs(filepath.Dir(path), root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEmptyDirs removes dir and its parents, up to root, while they are empty
+func removeEmptyDirs(dir, root string) error {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && len(dir) > len(root); dir = filepath.Dir(dir) {
+		empty, err := afero.IsEmpty(AppFS, dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil || !empty {
+			return err
+		}
+		if err = AppFS.Remove(dir); err != nil {
+			return errors.Wrapf(err, "failed to remove empty directory: %s", dir)
+		}
+	}
+	return nil
+}
+
+// within returns the path of f relative to root, and whether it stays inside root
+func within(root, f string) (string, bool) {
+	if filepath.IsAbs(f) {
+		return "", false
+	}
+	path := filepath.Join(root, f)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return path, true
+}
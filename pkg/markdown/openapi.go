@@ -0,0 +1,126 @@
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IsOpenAPI reports whether the document is an OpenAPI document rather than a json schema
+func IsOpenAPI(doc RawSchema) bool {
+	_, ok := doc["openapi"].(string)
+	return ok
+}
+
+// OpenAPISchema translates an OpenAPI 3.0 or 3.1 document into a json schema document
+// holding its components.schemas, titled after the api. The components are kept at the same
+// location, so #/components/schemas/X refs resolve, and every schema lacking a title is
+// titled after its name. The nullable, example, discriminator and boolean exclusive bound
+// keywords of OpenAPI 3.0 are translated to their json schema equivalents. The pointers of
+// the component schemas are returned along with the document.
+func OpenAPISchema(doc RawSchema) (RawSchema, []string, error) {
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, nil, fmt.Errorf(`unsupported OpenAPI version "%s", expected 3.0 or 3.1`, version)
+	}
+
+	dialect := DefaultDraft.URL
+	if d, ok := doc["jsonSchemaDialect"].(string); ok {
+		dialect = d
+	}
+
+	root := RawSchema{"$schema": dialect}
+	if info, ok := doc["info"].(map[string]interface{}); ok {
+		for _, key := range []string{"title", "description"} {
+			if value, ok := info[key]; ok {
+				root[key] = value
+			}
+		}
+	}
+
+	components, _ := doc["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	openAPI30 := strings.HasPrefix(version, "3.0")
+	pointers := translateComponents(schemas, "#/components/schemas", func(s RawSchema) {
+		translateOpenAPI(s, openAPI30)
+	})
+	root["components"] = map[string]interface{}{"schemas": schemas}
+	return root, pointers, nil
+}
+
+// translateComponents titles the schemas of a components map, translates each of their
+// schema objects, and returns their pointers sorted by name
+func translateComponents(schemas map[string]interface{}, pointer string, translate func(s RawSchema)) []string {
+	var names []string
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pointers []string
+	for _, name := range names {
+		schema, ok := schemas[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := schema["title"]; !ok {
+			schema["title"] = name
+		}
+		RawSchema(schema).Walk(func(s RawSchema, _ string) {
+			translate(s)
+		})
+		pointers = append(pointers, pointer+"/"+escapePointer(name))
+	}
+	return pointers
+}
+
+// translateOpenAPI translates the OpenAPI keywords of a schema object to json schema
+func translateOpenAPI(s RawSchema, openAPI30 bool) {
+	if example, ok := s["example"]; ok {
+		if _, ok := s["examples"]; !ok {
+			s["examples"] = []interface{}{example}
+		}
+		delete(s, "example")
+	}
+
+	// the discriminator property must be present, the Variants table documents its values
+	if discriminator, ok := s["discriminator"].(map[string]interface{}); ok {
+		if name, ok := discriminator["propertyName"].(string); ok {
+			required, _ := s["required"].([]interface{})
+			if !contains(required, name) {
+				s["required"] = append(required, name)
+			}
+		}
+	}
+
+	if !openAPI30 {
+		return
+	}
+
+	if nullable, ok := s["nullable"].(bool); ok {
+		delete(s, "nullable")
+		if nullable {
+			switch t := s["type"].(type) {
+			case string:
+				s["type"] = []interface{}{t, "null"}
+			case []interface{}:
+				if !contains(t, "null") {
+					s["type"] = append(t, "null")
+				}
+			}
+			if enum, ok := s["enum"].([]interface{}); ok && !contains(enum, nil) {
+				s["enum"] = append(enum, nil)
+			}
+		}
+	}
+
+	for exclusive, bound := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		if b, ok := s[exclusive].(bool); ok {
+			delete(s, exclusive)
+			if value, ok := s[bound]; ok && b {
+				s[exclusive] = value
+				delete(s, bound)
+			}
+		}
+	}
+}
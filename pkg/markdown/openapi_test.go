@@ -0,0 +1,125 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const petstore = `openapi: 3.0.3
+info:
+  title: Petstore
+  description: A sample pet store
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        200:
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: object
+      description: A pet of the store
+      required: [id]
+      discriminator:
+        propertyName: kind
+      properties:
+        id:
+          type: integer
+          minimum: 0
+          exclusiveMinimum: true
+        name:
+          type: string
+          nullable: true
+          example: Rex
+        kind:
+          type: string
+          enum: [dog, cat]
+          nullable: true
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestOpenAPISchema(t *testing.T) {
+	doc := RawSchema{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": "Petstore", "version": "1"},
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Pet": map[string]interface{}{
+					"discriminator": map[string]interface{}{"propertyName": "kind"},
+					"properties": map[string]interface{}{
+						"name":     map[string]interface{}{"type": "string", "nullable": true, "example": "Rex"},
+						"age":      map[string]interface{}{"type": "integer", "maximum": 30, "exclusiveMaximum": true},
+						"nullable": map[string]interface{}{"type": "boolean"},
+					},
+				},
+			},
+		},
+	}
+
+	schema, pointers, err := OpenAPISchema(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"#/components/schemas/Pet"}, pointers)
+	assert.Equal(t, DefaultDraft.URL, schema["$schema"])
+	assert.Equal(t, "Petstore", schema["title"])
+
+	pet := schema["components"].(map[string]interface{})["schemas"].(map[string]interface{})["Pet"].(map[string]interface{})
+	assert.Equal(t, "Pet", pet["title"])
+	assert.Equal(t, []interface{}{"kind"}, pet["required"])
+
+	properties := pet["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": []interface{}{"string", "null"}, "examples": []interface{}{"Rex"}}, properties["name"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "exclusiveMaximum": 30}, properties["age"])
+	// a property named after a keyword is left untouched
+	assert.Equal(t, map[string]interface{}{"type": "boolean"}, properties["nullable"])
+
+	_, _, err = OpenAPISchema(RawSchema{"openapi": "2.0"})
+	assert.NotNil(t, err)
+}
+
+func TestConverter_ConvertOpenAPI(t *testing.T) {
+	writeSchema(t, "/openapi/petstore.yaml", petstore)
+	cfg := config
+	cfg.Destination = "/openapi-output"
+	assert.Nil(t, NewConverter(cfg).Convert("/openapi/petstore.yaml"))
+
+	files := readTree(t, cfg.Destination)
+	assert.Contains(t, files["petstore/_index.md"], "**Description:** A sample pet store")
+	assert.Contains(t, files["petstore/_index.md"], "- [Pet]({{%baseurl%}}/reference/petstore/schemas/#pet): A pet of the store")
+	assert.Contains(t, files["petstore/_index.md"], "- [Owner]({{%baseurl%}}/reference/petstore/schemas/#owner)")
+
+	pet := files["petstore/schemas/pet.md"]
+	assert.Contains(t, pet, "| id | Integer |  | **Number:**<br>Exclusive Min: 0/1 |")
+	assert.Contains(t, pet, "| name | String<br/>Null |")
+	assert.Contains(t, pet, "| kind | String<br/>Null |  | Enum: [dog cat <nil>] |")
+	assert.Contains(t, pet, "| owner | [Owner]({{%baseurl%}}/reference/petstore/schemas/#owner) |")
+	assert.Contains(t, pet, "Required: [id, kind]")
+
+	assert.Contains(t, files["petstore/schemas/owner.md"], "- [Pet]({{%baseurl%}}/reference/petstore/schemas/#pet) `owner`")
+}
+
+func TestConverter_ConvertOpenAPISinglePage(t *testing.T) {
+	writeSchema(t, "/openapi-single/petstore.yaml", petstore)
+	cfg := config
+	cfg.Destination = "/openapi-single-output"
+	cfg.SinglePage = true
+	assert.Nil(t, NewConverter(cfg).Convert("/openapi-single/petstore.yaml"))
+
+	page := readTree(t, cfg.Destination)["petstore.md"]
+	assert.Contains(t, page, "- [Pet](#pet): A pet of the store")
+	assert.Contains(t, page, "## Owner")
+	assert.Contains(t, page, "## Pet")
+}
+
+func TestFilePath_components(t *testing.T) {
+	assert.Equal(t, "petstore/schemas", FilePath("file:///api/petstore.yaml#/components/schemas/Pet"))
+	assert.Equal(t, "petstore", FilePath("file:///api/petstore.yaml#/$defs/Pet"))
+}
@@ -0,0 +1,132 @@
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Reference is a $ref from a property of a schema page
+type Reference struct {
+	// Schema is the page the reference is made from
+	Schema *Schema
+
+	// Path is the property path of the reference within the page, e.g. dimensions.tags[]
+	Path string
+}
+
+// References maps the location of each referenced schema to the references made to it
+type References map[string][]Reference
+
+// NewReferences computes the reverse $ref graph of the schemas and their definitions
+func NewReferences(schemas []*Schema) References {
+	var pages []*Schema
+	unique := map[string]bool{}
+	for _, root := range schemas {
+		for _, s := range append([]*Schema{root}, root.Definitions()...) {
+			if !unique[s.Location] {
+				unique[s.Location] = true
+				pages = append(pages, s)
+			}
+		}
+	}
+
+	refs := References{}
+	for _, page := range pages {
+		seen := map[string]bool{}
+		page.WalkSchema(false, func(s *Schema) error {
+			for _, target := range []*jsonschema.Schema{s.Ref, s.DynamicRef, s.RecursiveRef} {
+				if target == nil {
+					continue
+				}
+				path := PropertyPath(page.Location, s.Location)
+				if key := target.Location + " " + path; !seen[key] {
+					seen[key] = true
+					refs[target.Location] = append(refs[target.Location], Reference{page, path})
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, list := range refs {
+		sort.SliceStable(list, func(i, j int) bool {
+			a, b := list[i], list[j]
+			ta, tb := FirstNonEmpty(a.Schema.Title, Humanize(a.Schema.Location)), FirstNonEmpty(b.Schema.Title, Humanize(b.Schema.Location))
+			if ta != tb {
+				return ta < tb
+			}
+			return a.Path < b.Path
+		})
+	}
+	return refs
+}
+
+// ReferencedBy returns the references made to the schema
+func (r References) ReferencedBy(s *Schema) []Reference {
+	if s == nil {
+		return nil
+	}
+	return r[s.Location]
+}
+
+// PropertyPath returns the path of the schema at location relative to its page, using
+// property names rather than keywords
+// file:///a.json#/properties/a/items/properties/b => a[].b
+func PropertyPath(page, location string) string {
+	pointer := strings.TrimPrefix(Pointer(location), Pointer(page))
+	tokens := strings.Split(strings.Trim(pointer, "/"), "/")
+
+	var path string
+	for i := 0; i < len(tokens); i++ {
+		token := unescapePointer(tokens[i])
+		next := ""
+		if i+1 < len(tokens) {
+			next = unescapePointer(tokens[i+1])
+		}
+
+		switch token {
+		case "":
+		case "properties":
+			if len(path) > 0 {
+				path += "."
+			}
+			path += next
+			i++
+		case "items", "prefixItems", "allOf", "anyOf", "oneOf":
+			prefix := ""
+			if token != "items" && token != "prefixItems" {
+				prefix = token
+				if len(path) > 0 {
+					prefix = "." + token
+				}
+			}
+			if isIndex(next) {
+				path += fmt.Sprintf("%s[%s]", prefix, next)
+				i++
+			} else {
+				path += prefix + "[]"
+			}
+		default:
+			if len(path) > 0 {
+				path += "."
+			}
+			path += token
+		}
+	}
+	return path
+}
+
+func isIndex(token string) bool {
+	if len(token) == 0 {
+		return false
+	}
+	for _, r := range token {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
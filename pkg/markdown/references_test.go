@@ -0,0 +1,46 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropertyPath(t *testing.T) {
+	testCases := map[string]string{
+		"file:///a.json#/properties/a":                         "a",
+		"file:///a.json#/properties/a/items/properties/b":      "a[].b",
+		"file:///a.json#/properties/a/items/0":                 "a[0]",
+		"file:///a.json#/properties/a/oneOf/1/properties/b":    "a.oneOf[1].b",
+		"file:///a.json#/properties/a~1b/additionalProperties": "a/b.additionalProperties",
+		"file:///a.json#/definitions/c/properties/d":           "d",
+		"file:///a.json#": "",
+	}
+
+	for location, expected := range testCases {
+		page := "file:///a.json#"
+		if location == "file:///a.json#/definitions/c/properties/d" {
+			page = "file:///a.json#/definitions/c"
+		}
+		assert.Equal(t, expected, PropertyPath(page, location), location)
+	}
+}
+
+func TestNewReferences(t *testing.T) {
+	writeSchema(t, "/references/order.schema.json", orderSchema)
+	c := NewConverter(config)
+	schemas, err := c.Load("/references")
+	assert.Nil(t, err)
+
+	refs := NewReferences(schemas)
+	customer := schemas[0].Properties["customer"].Ref
+	references := refs.ReferencedBy(ToSchema(customer, ""))
+	assert.Len(t, references, 1)
+	assert.Equal(t, "Order", references[0].Schema.Title)
+	assert.Equal(t, "customer", references[0].Path)
+
+	item := schemas[0].Properties["items"].Items2020
+	assert.Equal(t, "items[]", refs.ReferencedBy(ToSchema(item.Ref, ""))[0].Path)
+
+	assert.Empty(t, refs.ReferencedBy(schemas[0]))
+}
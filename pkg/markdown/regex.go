@@ -0,0 +1,310 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dlclark/regexp2"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// The regular expression engines the patterns rejected by the regexp package, e.g.
+// lookarounds, are compiled with
+const (
+	// RegexECMAScript compiles patterns with the ECMA-262 semantics json schema specifies
+	RegexECMAScript = "ecmascript"
+	// RegexGo compiles patterns with the RE2 syntax of the regexp package
+	RegexGo = "go"
+)
+
+// fallbackAdditional is the keyword the additionalProperties of a schema are moved to when
+// its pattern properties fell back to the regex engine, the compiler seeing every property
+// they match as an additional property
+const fallbackAdditional = "x-fallback-additionalProperties"
+
+// regexTimeout bounds the time an ECMA-262 pattern, which may backtrack, takes to match
+const regexTimeout = time.Second
+
+// Regex is a compiled pattern
+type Regex interface {
+	MatchString(s string) bool
+}
+
+// RegexEngine compiles patterns
+type RegexEngine interface {
+	Compile(pattern string) (Regex, error)
+}
+
+// RegexEngineFunc is a function compiling patterns
+type RegexEngineFunc func(pattern string) (Regex, error)
+
+func (f RegexEngineFunc) Compile(pattern string) (Regex, error) {
+	return f(pattern)
+}
+
+// GoRegexEngine compiles patterns with the regexp package
+var GoRegexEngine = RegexEngineFunc(func(pattern string) (Regex, error) {
+	return regexp.Compile(pattern)
+})
+
+// ECMAScriptRegexEngine compiles patterns with regexp2 in ECMAScript mode, which supports
+// lookarounds and backreferences
+var ECMAScriptRegexEngine = RegexEngineFunc(func(pattern string) (Regex, error) {
+	re, err := regexp2.Compile(pattern, regexp2.ECMAScript)
+	if err != nil {
+		return nil, err
+	}
+	re.MatchTimeout = regexTimeout
+	return ecmaRegex{re}, nil
+})
+
+type ecmaRegex struct {
+	re *regexp2.Regexp
+}
+
+func (r ecmaRegex) MatchString(s string) bool {
+	ok, err := r.re.MatchString(s)
+	return err == nil && ok
+}
+
+// SetRegexEngine sets the engine compiling the patterns which the regexp package rejects,
+// it must be set before the schemas are loaded
+func (c *Converter) SetRegexEngine(engine RegexEngine) {
+	c.engine = engine
+}
+
+// pageFallbacks returns the patterns of the page of s which the regexp package rejects, so the
+// page notes they are enforced by the regex engine
+func (c *Converter) pageFallbacks(s *Schema) []string {
+	found := map[string]bool{}
+	s.WalkSchema(false, func(s *Schema) error {
+		if s.Pattern != nil {
+			if pattern, ok := c.fallbackPattern(s.Pattern.String()); ok {
+				found[pattern] = true
+			}
+		}
+		for re := range s.PatternProperties {
+			if pattern, ok := c.fallbackPattern(re.String()); ok {
+				found[pattern] = true
+			}
+		}
+		return nil
+	})
+
+	var patterns []string
+	for pattern := range found {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// fallback records a pattern which the regexp package rejects and returns its placeholder.
+// The placeholder of a pattern matches every string, so the regex engine alone enforces it,
+// while the placeholder of a pattern property matches no property name.
+func (c *Converter) fallback(pattern string, property bool) string {
+	placeholder := fmt.Sprintf("(?:%s)?", Hash(pattern))
+	if property {
+		placeholder = Hash(pattern)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.fallbacks[pattern] {
+		log.Infof("pattern %s is not supported by the regexp package, falling back to the regex engine", pattern)
+		c.fallbacks[pattern] = true
+	}
+	c.patterns[placeholder] = pattern
+	return placeholder
+}
+
+// moveAdditional moves the additionalProperties of a schema whose pattern properties fell
+// back to the regex engine, so the pattern extension enforces them
+func (c *Converter) moveAdditional(m map[string]interface{}) {
+	additional, ok := m["additionalProperties"]
+	if !ok {
+		return
+	}
+	properties, _ := m["patternProperties"].(map[string]interface{})
+	for key := range properties {
+		if _, ok := c.fallbackPattern(key); ok {
+			m[fallbackAdditional] = additional
+			delete(m, "additionalProperties")
+			return
+		}
+	}
+}
+
+// AdditionalProperties returns the additionalProperties of s, a bool or a *jsonschema.Schema,
+// which the pattern extension holds when the pattern properties of s fell back to the regex
+// engine
+func AdditionalProperties(s *jsonschema.Schema) interface{} {
+	if s.AdditionalProperties != nil {
+		return s.AdditionalProperties
+	}
+	if p, ok := s.Extensions["patterns"].(patternSchema); ok {
+		return p.additional
+	}
+	return nil
+}
+
+// fallbackPattern returns the pattern a placeholder stands for
+func (c *Converter) fallbackPattern(placeholder string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	pattern, ok := c.patterns[placeholder]
+	return pattern, ok && c.fallbacks[pattern]
+}
+
+// patternExtension enforces the patterns which fell back to the regex engine
+type patternExtension struct {
+	c *Converter
+}
+
+// patternProperty is a pattern property which fell back to the regex engine
+type patternProperty struct {
+	key    string
+	re     Regex
+	schema *jsonschema.Schema
+}
+
+// patternSchema validates the pattern and pattern properties of a schema which fell back
+// to the regex engine, along with the additionalProperties of the schema, which need the
+// names of its properties and the patterns of its other pattern properties
+type patternSchema struct {
+	pattern    string
+	re         Regex
+	properties []patternProperty
+	additional interface{}
+	names      []string
+	patterns   []*regexp.Regexp
+}
+
+func (e patternExtension) Compile(ctx jsonschema.CompilerContext, m map[string]interface{}) (jsonschema.ExtSchema, error) {
+	var s patternSchema
+	if placeholder, ok := m["pattern"].(string); ok {
+		if pattern, ok := e.c.fallbackPattern(placeholder); ok {
+			s.pattern, s.re = pattern, e.compile(pattern)
+		}
+	}
+
+	properties, _ := m["patternProperties"].(map[string]interface{})
+	var keys []string
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		pattern, ok := e.c.fallbackPattern(key)
+		if !ok {
+			continue
+		}
+		re := e.compile(pattern)
+		if re == nil {
+			continue
+		}
+		schema, err := ctx.Compile("patternProperties/"+escapePointer(key), false)
+		if err != nil {
+			return nil, err
+		}
+		s.properties = append(s.properties, patternProperty{key, re, schema})
+	}
+
+	if additional, ok := m[fallbackAdditional]; ok {
+		s.additional = additional
+		if _, ok := additional.(bool); !ok {
+			schema, err := ctx.Compile(fallbackAdditional, false)
+			if err != nil {
+				return nil, err
+			}
+			schema.Location = strings.TrimSuffix(schema.Location, fallbackAdditional) + "additionalProperties"
+			s.additional = schema
+		}
+		names, _ := m["properties"].(map[string]interface{})
+		for name := range names {
+			s.names = append(s.names, name)
+		}
+		for _, key := range keys {
+			if _, ok := e.c.fallbackPattern(key); !ok {
+				s.patterns = append(s.patterns, regexp.MustCompile(key))
+			}
+		}
+	}
+
+	if s.re == nil && len(s.properties) == 0 && s.additional == nil {
+		return nil, nil
+	}
+	return s, nil
+}
+
+// compile compiles the pattern with the regex engine, patterns it rejects are not enforced
+func (e patternExtension) compile(pattern string) Regex {
+	re, err := e.c.engine.Compile(pattern)
+	if err != nil {
+		log.Warnf("pattern %s is not enforced: %v", pattern, err)
+		return nil
+	}
+	return re
+}
+
+func (s patternSchema) Validate(ctx jsonschema.ValidationContext, v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		if s.re != nil && !s.re.MatchString(v) {
+			return ctx.Error("pattern", "does not match pattern %q", s.pattern)
+		}
+	case map[string]interface{}:
+		var disallowed []string
+		for name, value := range v {
+			matched := false
+			for _, p := range s.properties {
+				if !p.re.MatchString(name) {
+					continue
+				}
+				matched = true
+				ctx.EvaluatedProp(name)
+				if err := ctx.Validate(p.schema, "patternProperties/"+escapePointer(p.key), value, escapePointer(name)); err != nil {
+					return err
+				}
+			}
+			if matched || s.additional == nil || s.declares(name) {
+				continue
+			}
+
+			ctx.EvaluatedProp(name)
+			switch additional := s.additional.(type) {
+			case bool:
+				if !additional {
+					disallowed = append(disallowed, fmt.Sprintf("%q", name))
+				}
+			case *jsonschema.Schema:
+				if err := ctx.Validate(additional, "additionalProperties", value, escapePointer(name)); err != nil {
+					return err
+				}
+			}
+		}
+		if len(disallowed) > 0 {
+			sort.Strings(disallowed)
+			return ctx.Error("additionalProperties", "additionalProperties %s not allowed", strings.Join(disallowed, ", "))
+		}
+	}
+	return nil
+}
+
+// declares returns whether the property name is one of the properties of the schema, or
+// matches one of its pattern properties the compiler enforces
+func (s patternSchema) declares(name string) bool {
+	if IndexOf(s.names, name) >= 0 {
+		return true
+	}
+	for _, re := range s.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
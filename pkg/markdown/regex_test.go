@@ -0,0 +1,106 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const lookaroundSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Account",
+  "type": "object",
+  "properties": {
+    "password": {"type": "string", "pattern": "^(?=.*[0-9])(?=.*[a-z]).{8,}$"},
+    "code": {"type": "string", "pattern": "^[A-Z]{3}$"}
+  },
+  "patternProperties": {
+    "^x-(?!internal)": {"type": "string"}
+  }
+}`
+
+func TestECMAScriptRegexEngine(t *testing.T) {
+	re, err := ECMAScriptRegexEngine.Compile(`^(?=.*\d)\w+$`)
+	assert.Nil(t, err)
+	assert.True(t, re.MatchString("abc1"))
+	assert.False(t, re.MatchString("abc"))
+
+	_, err = GoRegexEngine.Compile(`^(?=.*\d)\w+$`)
+	assert.NotNil(t, err)
+}
+
+func TestConfig_RegexEngine(t *testing.T) {
+	for _, val := range []string{"", RegexECMAScript, RegexGo} {
+		engine, err := Config{Regex: val}.RegexEngine()
+		assert.Nil(t, err)
+		assert.NotNil(t, engine)
+	}
+
+	_, err := Config{Regex: "pcre"}.RegexEngine()
+	assert.NotNil(t, err)
+}
+
+func TestConverter_fallbackPatterns(t *testing.T) {
+	writeSchema(t, "/regex/account.schema.json", lookaroundSchema)
+	cfg := config
+	cfg.Destination = "/regex-output"
+	c := NewConverter(cfg)
+	schemas, err := c.Load("/regex")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"^(?=.*[0-9])(?=.*[a-z]).{8,}$", "^x-(?!internal)"}, c.pageFallbacks(schemas[0]))
+
+	s := schemas[0]
+	assert.Equal(t, "^[A-Z]{3}$", s.Properties["code"].Pattern.String())
+
+	assert.Nil(t, s.Validate(map[string]interface{}{"password": "secret123", "code": "ABC", "x-tag": "a"}))
+	assert.NotNil(t, s.Validate(map[string]interface{}{"password": "secretsecret"}))
+	assert.NotNil(t, s.Validate(map[string]interface{}{"code": "abc"}))
+	assert.NotNil(t, s.Validate(map[string]interface{}{"x-tag": 1}))
+	assert.Nil(t, s.Validate(map[string]interface{}{"x-internal": 1}))
+
+	lookup := LookupRegex(c.patterns)
+	assert.Equal(t, "`^(?=.*[0-9])(?=.*[a-z]).{8,}$`", lookup(*s.Properties["password"].Pattern))
+	assert.Equal(t, "`^[A-Z]{3}$`", lookup(*s.Properties["code"].Pattern))
+
+	assert.Nil(t, c.Convert("/regex"))
+	page := readTree(t, cfg.Destination)["account-schema/_index.md"]
+	assert.Contains(t, page, "**Patterns enforced by the regex engine:**\n\n- `^(?=.*[0-9])(?=.*[a-z]).{8,}$`\n- `^x-(?!internal)`\n")
+}
+
+func TestConverter_fallbackPatternsAdditionalProperties(t *testing.T) {
+	writeSchema(t, "/regex-additional/closed.schema.json", `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Closed",
+  "type": "object",
+  "properties": {"code": {"type": "string"}},
+  "patternProperties": {"^x-(?!internal)": {"type": "string"}, "^y-": {"type": "string"}},
+  "additionalProperties": false
+}`)
+	writeSchema(t, "/regex-additional/typed.schema.json", `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Typed",
+  "type": "object",
+  "patternProperties": {"^x-(?!internal)": {"type": "string"}},
+  "additionalProperties": {"type": "integer"}
+}`)
+	cfg := config
+	cfg.Destination = "/regex-additional-output"
+	c := NewConverter(cfg)
+	schemas, err := c.Load("/regex-additional")
+	assert.Nil(t, err)
+
+	closed, typed := schemas[0], schemas[1]
+	assert.Nil(t, closed.Validate(map[string]interface{}{"code": "ABC", "x-tag": "a", "y-tag": "b"}))
+	assert.NotNil(t, closed.Validate(map[string]interface{}{"x-tag": 1}))
+	assert.NotNil(t, closed.Validate(map[string]interface{}{"x-internal": "a"}))
+	assert.NotNil(t, closed.Validate(map[string]interface{}{"other": "a"}))
+	assert.Equal(t, false, AdditionalProperties(closed.Schema))
+
+	assert.Nil(t, typed.Validate(map[string]interface{}{"x-tag": "a", "x-internal": 1}))
+	assert.NotNil(t, typed.Validate(map[string]interface{}{"x-internal": "a"}))
+	assert.NotNil(t, AsSchema(AdditionalProperties(typed.Schema)))
+
+	assert.Nil(t, c.Convert("/regex-additional"))
+	page := readTree(t, cfg.Destination)["closed-schema/_index.md"]
+	assert.Contains(t, page, "No additional properties allowed")
+}
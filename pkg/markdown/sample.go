@@ -0,0 +1,367 @@
+package markdown
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// sampleDepth is the depth after which only the required properties are synthesised, and
+// twice that is the depth at which synthesising stops, so recursive schemas terminate
+const sampleDepth = 8
+
+// sampleFormats are the values used for strings of a known format
+var sampleFormats = map[string]string{
+	"date-time":     "2024-01-01T00:00:00Z",
+	"date":          "2024-01-01",
+	"time":          "12:00:00Z",
+	"duration":      "P1D",
+	"email":         "user@example.com",
+	"idn-email":     "user@example.com",
+	"hostname":      "example.com",
+	"idn-hostname":  "example.com",
+	"ipv4":          "192.0.2.1",
+	"ipv6":          "2001:db8::1",
+	"uri":           "https://example.com",
+	"uri-reference": "https://example.com",
+	"iri":           "https://example.com",
+	"iri-reference": "https://example.com",
+	"uri-template":  "https://example.com/{id}",
+	"uuid":          "123e4567-e89b-12d3-a456-426614174000",
+	"json-pointer":  "/example",
+	"regex":         ".*",
+}
+
+// Sample synthesises an instance of the schema, using its examples, default, const or enum
+// values where present and its type, format and bounds otherwise. The minimal instance only
+// holds the required properties while the full instance holds every property. The instance
+// is validated against the schema before being returned.
+func Sample(s *jsonschema.Schema, full bool) (interface{}, error) {
+	if s == nil {
+		return nil, errors.New("no schema to sample")
+	}
+
+	instance := sample(s, full, 0)
+
+	// round trip through json so numbers are validated the way they are decoded
+	b, err := json.Marshal(instance)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(b)))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err = decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	if err = validateSample(s, decoded); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// SampleJSON returns the indented json of the sample instance, or an empty string if no
+// valid instance could be synthesised
+func SampleJSON(s *jsonschema.Schema, full bool) string {
+	instance, err := Sample(s, full)
+	if err != nil {
+		log.Warnf("omitting the sample of %s: %v", s.Location, err)
+		return ""
+	}
+	b, err := json.MarshalIndent(instance, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// validateSample validates the instance against the schema, patterns included
+func validateSample(s *jsonschema.Schema, instance interface{}) error {
+	return s.Validate(instance)
+}
+
+func sample(s *jsonschema.Schema, full bool, depth int) interface{} {
+	if s == nil || depth > 2*sampleDepth {
+		return nil
+	}
+	// only expand the required properties of deeply nested, possibly recursive, schemas
+	if depth > sampleDepth {
+		full = false
+	}
+
+	switch {
+	case len(s.Constant) > 0:
+		return s.Constant[0]
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	}
+
+	for _, ref := range []*jsonschema.Schema{s.Ref, s.DynamicRef, s.RecursiveRef} {
+		if ref != nil && len(s.Types) == 0 && len(s.Properties) == 0 {
+			return sample(ref, full, depth+1)
+		}
+	}
+
+	var branches []*jsonschema.Schema
+	branches = append(branches, s.AllOf...)
+	if len(s.OneOf) > 0 {
+		branches = append(branches, s.OneOf[0])
+	}
+	if len(s.AnyOf) > 0 {
+		branches = append(branches, s.AnyOf[0])
+	}
+
+	switch sampleType(s) {
+	case "object":
+		object := sampleObject(s, full, depth)
+		for _, branch := range branches {
+			if props, ok := sample(branch, full, depth+1).(map[string]interface{}); ok {
+				for k, v := range props {
+					if _, exists := object[k]; !exists {
+						object[k] = v
+					}
+				}
+			}
+		}
+		return object
+	case "array":
+		return sampleArray(s, full, depth)
+	case "string":
+		return sampleString(s)
+	case "integer":
+		return sampleNumber(s, true)
+	case "number":
+		return sampleNumber(s, false)
+	case "boolean":
+		return true
+	case "null":
+		return nil
+	}
+
+	for _, branch := range branches {
+		if v := sample(branch, full, depth+1); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// sampleType returns the type to synthesise, preferring any type over null
+func sampleType(s *jsonschema.Schema) string {
+	for _, t := range s.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	if len(s.Types) > 0 {
+		return s.Types[0]
+	}
+
+	switch {
+	case len(s.Properties) > 0:
+		return "object"
+	case s.Items != nil || s.Items2020 != nil || len(s.PrefixItems) > 0:
+		return "array"
+	case s.MinLength > 0 || s.MaxLength >= 0 || len(s.Format) > 0:
+		return "string"
+	case s.Minimum != nil || s.Maximum != nil || s.ExclusiveMinimum != nil || s.ExclusiveMaximum != nil:
+		return "number"
+	}
+
+	for _, branch := range s.AllOf {
+		if len(branch.Properties) > 0 || (branch.Ref != nil && len(branch.Ref.Properties) > 0) {
+			return "object"
+		}
+	}
+	return ""
+}
+
+func sampleObject(s *jsonschema.Schema, full bool, depth int) map[string]interface{} {
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	object := map[string]interface{}{}
+	for _, name := range names {
+		if full || required[name] {
+			object[name] = sample(s.Properties[name], full, depth+1)
+		}
+	}
+	return object
+}
+
+func sampleArray(s *jsonschema.Schema, full bool, depth int) []interface{} {
+	array := []interface{}{}
+	prefix := s.PrefixItems
+	if tuple, ok := s.Items.([]*jsonschema.Schema); ok {
+		prefix = tuple
+	}
+	for _, item := range prefix {
+		array = append(array, sample(item, full, depth+1))
+	}
+
+	var items *jsonschema.Schema
+	switch {
+	case s.Items2020 != nil:
+		items = s.Items2020
+	case IsSchema(s.Items):
+		items = s.Items.(*jsonschema.Schema)
+	}
+
+	count := s.MinItems
+	if full && count < len(prefix)+1 {
+		count = len(prefix) + 1
+	}
+	if s.MaxItems >= 0 && count > s.MaxItems {
+		count = s.MaxItems
+	}
+	for len(array) < count && (items != nil || len(prefix) == 0) {
+		array = append(array, sample(items, full, depth+1))
+	}
+	return array
+}
+
+func sampleString(s *jsonschema.Schema) string {
+	value, ok := sampleFormats[s.Format]
+	if !ok {
+		value = "string"
+	}
+	if pattern, re := samplePattern(s); re != nil && !re.MatchString(value) {
+		if matching, ok := patternString(pattern); ok {
+			value = matching
+		}
+	}
+	for s.MinLength > 0 && len([]rune(value)) < s.MinLength {
+		value += value
+	}
+	if s.MaxLength >= 0 && len([]rune(value)) > s.MaxLength {
+		value = string([]rune(value)[:s.MaxLength])
+	}
+	return value
+}
+
+// samplePattern returns the pattern of s and its compiled regex, which is the regex engine's
+// when the pattern fell back to it
+func samplePattern(s *jsonschema.Schema) (string, Regex) {
+	if p, ok := s.Extensions["patterns"].(patternSchema); ok && p.re != nil {
+		return p.pattern, p.re
+	}
+	if s.Pattern != nil {
+		return s.Pattern.String(), s.Pattern
+	}
+	return "", nil
+}
+
+// patternString returns a short string matching the pattern, following the first branch of
+// each alternation and the fewest repetitions. It returns false for the patterns the regexp
+// package cannot parse.
+func patternString(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if !writePattern(&b, re.Simplify()) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+func writePattern(b *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		b.WriteRune(re.Rune[0])
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune('a')
+	case syntax.OpCapture:
+		return writePattern(b, re.Sub[0])
+	case syntax.OpPlus:
+		return writePattern(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			if !writePattern(b, re.Sub[0]) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writePattern(b, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return writePattern(b, re.Sub[0])
+	}
+	return true
+}
+
+func sampleNumber(s *jsonschema.Schema, integer bool) json.Number {
+	one := big.NewRat(1, 1)
+	value := new(big.Rat)
+	if s.Minimum != nil {
+		value.Set(s.Minimum)
+	}
+	if s.ExclusiveMinimum != nil && value.Cmp(s.ExclusiveMinimum) <= 0 {
+		value.Add(s.ExclusiveMinimum, one)
+	}
+	if s.Maximum != nil && value.Cmp(s.Maximum) > 0 {
+		value.Set(s.Maximum)
+	}
+	if s.ExclusiveMaximum != nil && value.Cmp(s.ExclusiveMaximum) >= 0 {
+		value.Sub(s.ExclusiveMaximum, one)
+		if s.ExclusiveMinimum != nil && value.Cmp(s.ExclusiveMinimum) <= 0 {
+			value.Add(s.ExclusiveMinimum, s.ExclusiveMaximum)
+			value.Quo(value, big.NewRat(2, 1))
+		}
+	}
+
+	if integer {
+		value = ceil(value)
+	}
+	if s.MultipleOf != nil && s.MultipleOf.Sign() > 0 {
+		quotient := new(big.Rat).Quo(value, s.MultipleOf)
+		value.Mul(ceil(quotient), s.MultipleOf)
+	}
+
+	if value.IsInt() {
+		return json.Number(value.Num().String())
+	}
+	f, _ := value.Float64()
+	return json.Number(big.NewFloat(f).Text('f', -1))
+}
+
+func ceil(r *big.Rat) *big.Rat {
+	if r.IsInt() {
+		return new(big.Rat).Set(r)
+	}
+	q := new(big.Int).Quo(r.Num(), r.Denom())
+	if r.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return new(big.Rat).SetInt(q)
+}
@@ -0,0 +1,92 @@
+package markdown
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func compileString(t *testing.T, schema string) *jsonschema.Schema {
+	compiler := jsonschema.NewCompiler()
+	compiler.ExtractAnnotations = true
+	assert.Nil(t, compiler.AddResource("sample.json", strings.NewReader(schema)))
+	s, err := compiler.Compile("sample.json")
+	assert.Nil(t, err)
+	return s
+}
+
+func TestSample(t *testing.T) {
+	s := compileString(t, `{
+		"type": "object",
+		"required": ["id", "kind", "count"],
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"kind": {"enum": ["a", "b"]},
+			"count": {"type": "integer", "exclusiveMinimum": 2, "multipleOf": 5},
+			"ratio": {"type": "number", "maximum": -1.5},
+			"name": {"type": "string", "minLength": 10, "maxLength": 12},
+			"tags": {"type": "array", "items": {"const": "x"}, "minItems": 2},
+			"note": {"type": "string", "examples": ["hello"]},
+			"flag": {"type": ["null", "boolean"], "default": false}
+		}
+	}`)
+
+	minimal, err := Sample(s, false)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":    "123e4567-e89b-12d3-a456-426614174000",
+		"kind":  "a",
+		"count": json.Number("5"),
+	}, minimal)
+
+	full, err := Sample(s, true)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":    "123e4567-e89b-12d3-a456-426614174000",
+		"kind":  "a",
+		"count": json.Number("5"),
+		"ratio": json.Number("-1.5"),
+		"name":  "stringstring",
+		"tags":  []interface{}{"x", "x"},
+		"note":  "hello",
+		"flag":  false,
+	}, full)
+}
+
+func TestSampleInvalid(t *testing.T) {
+	s := compileString(t, `{"type": "string", "not": {"type": "string"}}`)
+	_, err := Sample(s, false)
+	assert.NotNil(t, err)
+	assert.Equal(t, "", SampleJSON(s, false))
+}
+
+func TestSampleRecursive(t *testing.T) {
+	s := compileString(t, `{
+		"$defs": {"node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}}}},
+		"$ref": "#/$defs/node"
+	}`)
+	assert.NotEqual(t, "", SampleJSON(s, true))
+}
+
+func TestSamplePattern(t *testing.T) {
+	s := compileString(t, `{
+		"type": "object",
+		"required": ["code", "sku"],
+		"properties": {
+			"code": {"type": "string", "pattern": "^[A-Z]{3}-\\d{2,4}$"},
+			"sku": {"type": "string", "pattern": "^(abc|def)+x?$"}
+		}
+	}`)
+	minimal, err := Sample(s, false)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"code": "AAA-00", "sku": "abc"}, minimal)
+
+	// an instance failing the pattern is not published
+	s = compileString(t, `{"type": "string", "pattern": "^a$", "minLength": 2}`)
+	_, err = Sample(s, false)
+	assert.NotNil(t, err)
+	assert.Equal(t, "", SampleJSON(s, false))
+}
@@ -1,6 +1,9 @@
 package markdown
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	log "github.com/sirupsen/logrus"
 )
@@ -31,6 +34,45 @@ func (r RawSchema) Id() string {
 	return ""
 }
 
+// Walk calls fn with the raw schema and each of its subschemas, parents first, along with
+// their json pointer, e.g. #/properties/a
+func (r RawSchema) Walk(fn func(schema RawSchema, pointer string)) {
+	r.walk("#", fn)
+}
+
+func (r RawSchema) walk(pointer string, fn func(schema RawSchema, pointer string)) {
+	fn(r, pointer)
+
+	for _, key := range schemaKeywords {
+		if sub, ok := r[key].(map[string]interface{}); ok {
+			RawSchema(sub).walk(pointer+"/"+escapePointer(key), fn)
+		}
+	}
+	for _, key := range schemaListKeywords {
+		if list, ok := r[key].([]interface{}); ok {
+			for i, item := range list {
+				if sub, ok := item.(map[string]interface{}); ok {
+					RawSchema(sub).walk(fmt.Sprintf("%s/%s/%d", pointer, key, i), fn)
+				}
+			}
+		}
+	}
+	for _, key := range schemaMapKeywords {
+		if m, ok := r[key].(map[string]interface{}); ok {
+			var names []string
+			for name := range m {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if sub, ok := m[name].(map[string]interface{}); ok {
+					RawSchema(sub).walk(pointer+"/"+escapePointer(key)+"/"+escapePointer(name), fn)
+				}
+			}
+		}
+	}
+}
+
 // Definitions returns all references from the current schema
 func (s *Schema) Definitions() []*Schema {
 	var definitions []*Schema
@@ -44,6 +86,20 @@ func (s *Schema) Definitions() []*Schema {
 	return definitions
 }
 
+// UniqueDefinitions returns the references from the current schema once each, excluding
+// the schema itself
+func (s *Schema) UniqueDefinitions() []*Schema {
+	var definitions []*Schema
+	unique := map[string]bool{s.Location: true}
+	for _, def := range s.Definitions() {
+		if !unique[def.Location] {
+			unique[def.Location] = true
+			definitions = append(definitions, def)
+		}
+	}
+	return definitions
+}
+
 // WalkSchema walks the schema tree, calling fn for each schema in the tree, including root.
 func (s *Schema) WalkSchema(followRef bool, fn func(s *Schema) error) {
 	s.walkSchema(followRef, map[string]bool{}, fn)
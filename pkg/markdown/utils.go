@@ -1,7 +1,9 @@
 package markdown
 
 import (
+	"bytes"
 	"crypto/md5"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -9,6 +11,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -17,6 +21,7 @@ import (
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
 )
 
 func Slugify(s string) string {
@@ -79,6 +84,16 @@ func AnchorPath(path string) string {
 	return path[i+1:]
 }
 
+// Pointer returns the unescaped json pointer after the anchor (#)
+// /a/b/c#/d%20e => /d e
+func Pointer(location string) string {
+	anchor := AnchorPath(location)
+	if p, err := url.PathUnescape(anchor); err == nil {
+		return p
+	}
+	return anchor
+}
+
 func Humanize(path string) string {
 	base := FilenameWithoutExt(path)
 	base = strings.TrimSuffix(base, "#")
@@ -107,8 +122,18 @@ func FirstNonEmpty(values ...string) string {
 	return ""
 }
 
+// firstError returns the first of the errors which is not nil
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func IsSlice(v interface{}) bool {
-	return reflect.TypeOf(v).Kind() == reflect.Slice
+	return v != nil && reflect.TypeOf(v).Kind() == reflect.Slice
 }
 
 func IsSchema(v interface{}) bool {
@@ -122,6 +147,49 @@ func IsSchema(v interface{}) bool {
 	}
 }
 
+// AsSchema returns the schema of a keyword which holds a schema or a boolean, or nil when
+// it holds a boolean or a boolean schema
+func AsSchema(v interface{}) *jsonschema.Schema {
+	s, ok := v.(*jsonschema.Schema)
+	if !ok || s == nil || s.Always != nil {
+		return nil
+	}
+	return s
+}
+
+// additionalOf returns the additionalProperties of a *Schema or *jsonschema.Schema
+func additionalOf(v interface{}) interface{} {
+	switch s := v.(type) {
+	case *Schema:
+		return AdditionalProperties(s.Schema)
+	case *jsonschema.Schema:
+		return AdditionalProperties(s)
+	}
+	return nil
+}
+
+// Allows returns "true" or "false" for a keyword which holds a boolean or a boolean schema,
+// and an empty string otherwise
+func Allows(v interface{}) string {
+	switch b := v.(type) {
+	case bool:
+		return strconv.FormatBool(b)
+	case *jsonschema.Schema:
+		if b != nil && b.Always != nil {
+			return strconv.FormatBool(*b.Always)
+		}
+	}
+	return ""
+}
+
+// JoinPath appends the name to the dotted path
+func JoinPath(path, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
+
 func Slice(items ...string) []string {
 	var s []string
 	s = append(s, items...)
@@ -139,9 +207,10 @@ func EscapeRegex(v string) string {
 
 func LookupRegex(patterns map[string]string) func(regexp.Regexp) string {
 	return func(s regexp.Regexp) string {
+		// only the patterns the regexp package rejects are replaced by a placeholder
 		regex, ok := patterns[s.String()]
 		if !ok {
-			return ""
+			return EscapeRegex(s.String())
 		}
 		return EscapeRegex(regex)
 	}
@@ -169,6 +238,15 @@ func FilePath(location string) string {
 	if strings.HasPrefix(location[i:], "#/definitions") {
 		return filepath.Join(root, "definitions")
 	}
+	// the components of an OpenAPI or AsyncAPI document, e.g. #/components/schemas/Pet => <root>/schemas,
+	// and the channels of an AsyncAPI document
+	tokens := strings.Split(location[i+1:], "/")
+	if len(tokens) > 3 && tokens[1] == "components" {
+		return filepath.Join(root, Slugify(tokens[2]))
+	}
+	if len(tokens) > 2 && tokens[1] == "channels" {
+		return filepath.Join(root, "channels")
+	}
 	return root
 }
 
@@ -183,6 +261,68 @@ func GetPermalink(ref string) func(schema *jsonschema.Schema) string {
 	}
 }
 
+// GetRelativeLink returns a link to the page of the schema relative to the page at path, as
+// a hugo relref shortcode or, when plain is set, a markdown link. The pages are the paths of
+// the pages by the location of their schema, the schemas without a page of their own being
+// linked to the index of their section.
+func GetRelativeLink(destination, path string, pages map[string]string, plain bool) func(schema *jsonschema.Schema) string {
+	return func(schema *jsonschema.Schema) string {
+		title := FirstNonEmpty(schema.Title, Humanize(schema.Location))
+		target, ok := pages[schema.Location]
+		if !ok {
+			target = filepath.Join(destination, FilePath(schema.Location), "_index.md")
+		}
+		rel, err := filepath.Rel(filepath.Dir(path), target)
+		if err != nil {
+			rel = target
+		}
+
+		link := fmt.Sprintf("%s#%s", filepath.ToSlash(rel), Anchor(schema))
+		if plain {
+			return fmt.Sprintf("[%s](%s)", title, link)
+		}
+		return fmt.Sprintf(`[%s]({{< relref "%s" >}})`, title, link)
+	}
+}
+
+// GetAnchorLink returns a link to the section of the schema within the same page, whose id
+// is given by anchor
+func GetAnchorLink(anchor func(schema *jsonschema.Schema) string) func(schema *jsonschema.Schema) string {
+	return func(schema *jsonschema.Schema) string {
+		title := FirstNonEmpty(schema.Title, Humanize(schema.Location))
+		return fmt.Sprintf("[%s](#%s)", title, anchor(schema))
+	}
+}
+
+// Anchor returns the id of the section of the schema within a single page
+func Anchor(schema *jsonschema.Schema) string {
+	return FileName(schema.Title, schema.Location)
+}
+
+// Composition is an allOf, anyOf or oneOf composition of a schema
+type Composition struct {
+	// Kind is the keyword of the composition in PascalCase, e.g. OneOf
+	Kind     string
+	Branches []*jsonschema.Schema
+	// Union is set for the composition whose discriminated variants FindUnion looks for
+	Union bool
+}
+
+// Compositions returns the allOf, anyOf and oneOf compositions of s, in that order
+func Compositions(s *Schema) []Composition {
+	var compositions []Composition
+	for _, c := range []Composition{
+		{"AllOf", s.AllOf, false},
+		{"AnyOf", s.AnyOf, len(s.OneOf) == 0},
+		{"OneOf", s.OneOf, true},
+	} {
+		if len(c.Branches) > 0 {
+			compositions = append(compositions, c)
+		}
+	}
+	return compositions
+}
+
 func FindTypeOfs(s *Schema) []*Schema {
 	var schemas []*Schema
 	var unique = map[string]bool{}
@@ -200,6 +340,142 @@ func FindTypeOfs(s *Schema) []*Schema {
 	return schemas
 }
 
+// Breakout is a nested object schema rendered in a table of its own rather than inline
+type Breakout struct {
+	// Name is the dotted path of the schema within its page
+	Name   string
+	Schema *Schema
+}
+
+// GetBreakOut reports whether a property at depth is an object rendered in a table of its
+// own, which happens from the max inline depth on. A max of 0 inlines every depth.
+func GetBreakOut(max int) func(s *jsonschema.Schema, depth int) bool {
+	return func(s *jsonschema.Schema, depth int) bool {
+		return max > 0 && depth >= max && (len(s.Properties) > 0 || len(s.PatternProperties) > 0)
+	}
+}
+
+// GetFindBreakouts returns the nested object schemas of a page which are rendered in a table
+// of their own, following the rows rendered by the property template.
+func GetFindBreakouts(max int, patterns map[string]string) func(s *Schema) []Breakout {
+	breakOut := GetBreakOut(max)
+	lookup := LookupRegex(patterns)
+
+	return func(s *Schema) []Breakout {
+		var breakouts []Breakout
+		var children, items func(s *jsonschema.Schema, path string, depth int)
+		var property func(s *jsonschema.Schema, path string, depth int)
+
+		property = func(p *jsonschema.Schema, path string, depth int) {
+			if breakOut(p, depth) {
+				breakouts = append(breakouts, Breakout{path, ToSchema(p, s.Path)})
+				children(p, path, 1)
+			} else {
+				children(p, path, depth+1)
+			}
+			items(p, path, depth+1)
+		}
+
+		children = func(p *jsonschema.Schema, path string, depth int) {
+			var names []string
+			for name := range p.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				property(p.Properties[name], JoinPath(path, name), depth)
+			}
+
+			var patterns []*regexp.Regexp
+			for pattern := range p.PatternProperties {
+				patterns = append(patterns, pattern)
+			}
+			sort.Slice(patterns, func(i, j int) bool { return patterns[i].String() < patterns[j].String() })
+			for _, pattern := range patterns {
+				property(p.PatternProperties[pattern], JoinPath(path, lookup(*pattern)), depth)
+			}
+
+			if additional := AsSchema(AdditionalProperties(p)); additional != nil {
+				property(additional, JoinPath(path, "(additional)"), depth)
+			}
+			if unevaluated := AsSchema(p.UnevaluatedProperties); unevaluated != nil {
+				property(unevaluated, JoinPath(path, "(unevaluated)"), depth)
+			}
+		}
+
+		items = func(p *jsonschema.Schema, path string, depth int) {
+			for i, item := range p.PrefixItems {
+				property(item, fmt.Sprintf("%s[%d]", path, i), depth)
+			}
+			switch tuple := p.Items.(type) {
+			case []*jsonschema.Schema:
+				for i, item := range tuple {
+					property(item, fmt.Sprintf("%s[%d]", path, i), depth)
+				}
+			case *jsonschema.Schema:
+				if item := AsSchema(tuple); item != nil {
+					property(item, path+"[]", depth)
+				}
+			}
+			if item := AsSchema(p.Items2020); item != nil {
+				property(item, path+"[]", depth)
+			}
+			if additional := AsSchema(p.AdditionalItems); additional != nil {
+				property(additional, path+"[additional]", depth)
+			}
+			if unevaluated := AsSchema(p.UnevaluatedItems); unevaluated != nil {
+				property(unevaluated, path+"[unevaluated]", depth)
+			}
+			if contains := AsSchema(p.Contains); contains != nil {
+				property(contains, path+"[contains]", depth)
+			}
+		}
+
+		// the rows of the property, pattern property and array item tables of the page
+		children(s.Schema, "", 1)
+		items(s.Schema, "", 1)
+
+		// the rows of the inline tables of the allOf, anyOf and oneOf sub-schemas
+		for _, typeOf := range FindTypeOfs(s) {
+			for i, sub := range append(append(append([]*jsonschema.Schema{}, typeOf.AllOf...), typeOf.AnyOf...), typeOf.OneOf...) {
+				property(sub, fmt.Sprintf("ItemType[%d]", i), 1)
+			}
+		}
+		return breakouts
+	}
+}
+
+// Percent formats n out of total as a percentage, or "-" when total is zero
+func Percent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
+
+// Summary returns the first line of a description, escaped for a markdown table cell
+func Summary(description string) string {
+	line := strings.TrimSpace(description)
+	if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+		line = strings.TrimSpace(line[:i])
+	}
+	return strings.ReplaceAll(line, "|", "\\|")
+}
+
+// CodeSpan returns the value as an inline code span which can be put in a table cell: pipes
+// are escaped and the span is delimited by more backticks than the value holds
+func CodeSpan(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	fence := "`"
+	for strings.Contains(value, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(value, "`") || strings.HasSuffix(value, "`") {
+		value = " " + value + " "
+	}
+	return fence + value + fence
+}
+
 func IndexOf(slice []string, val string) int {
 	for i, s := range slice {
 		if s == val {
@@ -261,12 +537,23 @@ func FuncMap(ref string, patterns map[string]string, order map[string]*orderedma
 		"isSchema":      IsSchema,
 		"lookupRegex":   LookupRegex(patterns),
 		"permalink":     GetPermalink(ref),
+		"anchor":        Anchor,
 		"weight":        GetWeight(order),
 		"findTypeOfs":   FindTypeOfs,
+		"compositions":  Compositions,
 		"humanize":      Humanize,
 		"slice":         Slice,
 		"append":        Append,
 		"title":         Title,
+		"percent":       Percent,
+		"inc":           func(i int) int { return i + 1 },
+		"asSchema":      AsSchema,
+		"allows":        Allows,
+		"additional":    additionalOf,
+		"joinPath":      JoinPath,
+		"summary":       Summary,
+		"code":          CodeSpan,
+		"sample":        SampleJSON,
 	}
 }
 
@@ -276,3 +563,97 @@ func PathExist(path string) bool {
 	}
 	return true
 }
+
+// jsonValue converts the maps decoded from yaml, whose keys may not be strings, to maps
+// which can be encoded as json
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = jsonValue(value)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonValue(value)
+		}
+		return v
+	}
+	return v
+}
+
+// yamlJSON encodes a yaml node as json, keeping the keys of its mappings in the order they
+// are declared
+func yamlJSON(n *yaml.Node) ([]byte, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return []byte("null"), nil
+		}
+		return yamlJSON(n.Content[0])
+	case yaml.AliasNode:
+		return yamlJSON(n.Alias)
+	case yaml.SequenceNode:
+		var b bytes.Buffer
+		b.WriteByte('[')
+		for i, item := range n.Content {
+			v, err := yamlJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.Write(v)
+		}
+		b.WriteByte(']')
+		return b.Bytes(), nil
+	case yaml.MappingNode:
+		if !hasMergeKey(n) {
+			var b bytes.Buffer
+			b.WriteByte('{')
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				var key interface{}
+				if err := n.Content[i].Decode(&key); err != nil {
+					return nil, err
+				}
+				k, _ := json.Marshal(fmt.Sprint(key))
+				v, err := yamlJSON(n.Content[i+1])
+				if err != nil {
+					return nil, err
+				}
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.Write(k)
+				b.WriteByte(':')
+				b.Write(v)
+			}
+			b.WriteByte('}')
+			return b.Bytes(), nil
+		}
+		// the keys merged from other mappings are resolved by the decoder, at the cost of
+		// their order
+	}
+
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonValue(v))
+}
+
+func hasMergeKey(n *yaml.Node) bool {
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Tag == "!!merge" {
+			return true
+		}
+	}
+	return false
+}
@@ -211,6 +211,20 @@ func TestGetUrl(t *testing.T) {
 	assert.Equal(t, "[dimensions]({{%baseurl%}}/reference/sample-schema/#dimensions)", actual)
 }
 
+func TestGetRelativeLink(t *testing.T) {
+	schema := &jsonschema.Schema{Title: "sample", Location: "/test/ref.schema.json#/definitions/address"}
+
+	link := GetRelativeLink("/out", "/out/sample-schema/_index.md", nil, false)
+	assert.Equal(t, `[sample]({{< relref "../ref-schema/definitions/_index.md#sample" >}})`, link(schema))
+
+	link = GetRelativeLink("/out", "/out/sample-schema/_index.md", nil, true)
+	assert.Equal(t, "[sample](../ref-schema/definitions/_index.md#sample)", link(schema))
+
+	pages := map[string]string{schema.Location: "/out/ref-schema/definitions/01-sample.md"}
+	link = GetRelativeLink("/out", "/out/sample-schema/_index.md", pages, true)
+	assert.Equal(t, "[sample](../ref-schema/definitions/01-sample.md#sample)", link(schema))
+}
+
 func TestFilenameWithoutExt(t *testing.T) {
 	testCases := map[string]string{
 		"test/ref.schema.json#": "ref.schema",
@@ -250,6 +264,19 @@ func TestGetAnchorPath(t *testing.T) {
 	}
 }
 
+func TestPointer(t *testing.T) {
+	testCases := map[string]string{
+		"ref.schema.json#/properties/first%20name": "/properties/first name",
+		"ref.schema.json#/properties/a~1b":         "/properties/a~1b",
+		"ref.schema.json#":                         "",
+	}
+
+	for val, expected := range testCases {
+		actual := Pointer(val)
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestTrimAnchor(t *testing.T) {
 	testCases := map[string]string{
 		"ref.schema.json#/definitions/pagination/properties/first": "ref.schema.json",
@@ -293,3 +320,89 @@ func TestGetWeight(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	}
 }
+
+func TestPercent(t *testing.T) {
+	assert.Equal(t, "-", Percent(0, 0))
+	assert.Equal(t, "50.0%", Percent(1, 2))
+	assert.Equal(t, "33.3%", Percent(1, 3))
+	assert.Equal(t, "100.0%", Percent(3, 3))
+}
+
+const deepSchema = `{
+  "title": "Deep",
+  "type": "object",
+  "properties": {
+    "a": {
+      "type": "object",
+      "description": "level one",
+      "properties": {
+        "b": {
+          "type": "object",
+          "properties": {
+            "c": {"type": "object", "properties": {"d": {"type": "string"}}}
+          },
+          "patternProperties": {"^x-": {"type": "string"}}
+        },
+        "list": {"type": "array", "items": {"type": "object", "properties": {"e": {"type": "integer"}}}}
+      }
+    }
+  }
+}`
+
+func TestGetFindBreakouts(t *testing.T) {
+	writeSchema(t, "/breakouts/deep.schema.json", deepSchema)
+	c := NewConverter(config)
+	schemas, err := c.Load("/breakouts")
+	assert.Nil(t, err)
+
+	var names []string
+	for _, b := range GetFindBreakouts(2, c.patterns)(schemas[0]) {
+		names = append(names, b.Name)
+	}
+	assert.Equal(t, []string{"a.b", "a.list[]"}, names)
+
+	names = nil
+	for _, b := range GetFindBreakouts(1, c.patterns)(schemas[0]) {
+		names = append(names, b.Name)
+	}
+	assert.Equal(t, []string{"a", "a.b", "a.b.c", "a.list[]"}, names)
+
+	assert.Empty(t, GetFindBreakouts(0, c.patterns)(schemas[0]))
+}
+
+func TestGetBreakOut(t *testing.T) {
+	object := &jsonschema.Schema{Properties: map[string]*jsonschema.Schema{"a": {}}}
+	assert.False(t, GetBreakOut(0)(object, 5))
+	assert.False(t, GetBreakOut(2)(object, 1))
+	assert.True(t, GetBreakOut(2)(object, 2))
+	assert.False(t, GetBreakOut(2)(&jsonschema.Schema{}, 2))
+}
+
+func TestAsSchema(t *testing.T) {
+	always := false
+	schema := &jsonschema.Schema{}
+	assert.Equal(t, schema, AsSchema(schema))
+	assert.Nil(t, AsSchema(&jsonschema.Schema{Always: &always}))
+	assert.Nil(t, AsSchema(false))
+	assert.Nil(t, AsSchema(nil))
+}
+
+func TestAllows(t *testing.T) {
+	always := true
+	assert.Equal(t, "false", Allows(false))
+	assert.Equal(t, "true", Allows(&jsonschema.Schema{Always: &always}))
+	assert.Equal(t, "", Allows(&jsonschema.Schema{}))
+	assert.Equal(t, "", Allows(nil))
+}
+
+func TestJoinPath(t *testing.T) {
+	assert.Equal(t, "a", JoinPath("", "a"))
+	assert.Equal(t, "a.b", JoinPath("a", "b"))
+}
+
+func TestCodeSpan(t *testing.T) {
+	assert.Equal(t, "`\"created\"`", CodeSpan(`"created"`))
+	assert.Equal(t, "`\"a\\|b\"`", CodeSpan(`"a|b"`))
+	assert.Equal(t, "``\"a`b\"``", CodeSpan("\"a`b\""))
+	assert.Equal(t, "`` `a` ``", CodeSpan("`a`"))
+}
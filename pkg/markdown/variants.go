@@ -0,0 +1,195 @@
+package markdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Variant is a branch of a discriminated union
+type Variant struct {
+	// Value is the json encoded value of the discriminator property selecting the branch
+	Value string
+
+	// Name is the title of the branch, or its position when it has none, e.g. ItemType[1]
+	Name string
+
+	// Schema is the branch, links are made to the schema it references
+	Schema *jsonschema.Schema
+}
+
+// Union is a oneOf or anyOf composition whose branches are told apart by the value of a
+// discriminator property
+type Union struct {
+	// Property is the name of the discriminator property
+	Property string
+
+	Variants []Variant
+}
+
+// FindUnion returns the discriminated union of the oneOf or anyOf composition of s, or nil
+// when its branches are not discriminated. The OpenAPI discriminator keyword of the raw
+// schema is used when present, otherwise the discriminator is a property every branch
+// declares with a distinct const or single enum value.
+func FindUnion(s *jsonschema.Schema, raw RawSchema) *Union {
+	branches := s.OneOf
+	if len(branches) == 0 {
+		branches = s.AnyOf
+	}
+	if len(branches) < 2 {
+		return nil
+	}
+
+	if discriminator, ok := raw["discriminator"].(map[string]interface{}); ok {
+		if name, ok := discriminator["propertyName"].(string); ok && len(name) > 0 {
+			mapping, _ := discriminator["mapping"].(map[string]interface{})
+			return openAPIUnion(name, mapping, branches)
+		}
+	}
+
+	for _, name := range commonProperties(branches) {
+		if union := constUnion(name, branches); union != nil {
+			return union
+		}
+	}
+	return nil
+}
+
+// openAPIUnion maps the branches to the values of the discriminator property, taken from
+// the mapping, the const value of the property, or the name of the referenced schema
+func openAPIUnion(name string, mapping map[string]interface{}, branches []*jsonschema.Schema) *Union {
+	union := &Union{Property: name}
+	for i, b := range branches {
+		value, ok := mappedValue(mapping, b)
+		if !ok {
+			value, ok = discriminatorValue(b, name)
+		}
+		if !ok && b.Ref != nil {
+			value, ok = encode(schemaName(b.Ref.Location)), true
+		}
+		if ok {
+			union.Variants = append(union.Variants, variant(value, i, b))
+		}
+	}
+
+	if len(union.Variants) == 0 {
+		return nil
+	}
+	return union
+}
+
+// constUnion maps the branches to the const value of the property, provided every branch
+// has a distinct value
+func constUnion(name string, branches []*jsonschema.Schema) *Union {
+	union := &Union{Property: name}
+	values := map[string]bool{}
+	for i, b := range branches {
+		value, ok := discriminatorValue(b, name)
+		if !ok || values[value] {
+			return nil
+		}
+		values[value] = true
+		union.Variants = append(union.Variants, variant(value, i, b))
+	}
+	return union
+}
+
+func variant(value string, i int, b *jsonschema.Schema) Variant {
+	name := fmt.Sprintf("ItemType[%d]", i)
+	if target := resolve(b); len(target.Title) > 0 {
+		name = target.Title
+	}
+	return Variant{value, name, b}
+}
+
+// mappedValue returns the key of the mapping which references the branch
+func mappedValue(mapping map[string]interface{}, b *jsonschema.Schema) (string, bool) {
+	if b.Ref == nil {
+		return "", false
+	}
+
+	var keys []string
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	location := strings.TrimSuffix(b.Ref.Location, "#")
+	for _, key := range keys {
+		ref, _ := mapping[key].(string)
+		if !strings.ContainsAny(ref, "#/") {
+			// a bare schema name, e.g. Dog for #/components/schemas/Dog
+			ref = "/" + ref
+		}
+		if len(ref) > 1 && strings.HasSuffix(location, ref) {
+			return encode(key), true
+		}
+	}
+	return "", false
+}
+
+// discriminatorValue returns the json encoded const, or single enum, value of the property
+// of the branch
+func discriminatorValue(b *jsonschema.Schema, name string) (string, bool) {
+	p, ok := resolve(b).Properties[name]
+	if !ok {
+		return "", false
+	}
+
+	p = resolve(p)
+	switch {
+	case len(p.Constant) > 0:
+		return encode(p.Constant[0]), true
+	case len(p.Enum) == 1:
+		return encode(p.Enum[0]), true
+	}
+	return "", false
+}
+
+// commonProperties returns the names of the properties declared by every branch
+func commonProperties(branches []*jsonschema.Schema) []string {
+	var names []string
+	for name := range resolve(branches[0]).Properties {
+		common := true
+		for _, b := range branches[1:] {
+			if _, ok := resolve(b).Properties[name]; !ok {
+				common = false
+				break
+			}
+		}
+		if common {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaName returns the name of the schema at location, the last token of its pointer or
+// the name of its file
+func schemaName(location string) string {
+	if pointer := Pointer(location); len(strings.Trim(pointer, "/")) > 0 {
+		return unescapePointer(path.Base(pointer))
+	}
+	return FilenameWithoutExt(TrimAnchorPath(location))
+}
+
+// resolve follows the references of a schema which only references another
+func resolve(s *jsonschema.Schema) *jsonschema.Schema {
+	for i := 0; s.Ref != nil && len(s.Properties) == 0 && i < 32; i++ {
+		s = s.Ref
+	}
+	return s
+}
+
+func encode(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,128 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const unionSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Event",
+  "$defs": {
+    "created": {
+      "title": "Created",
+      "type": "object",
+      "properties": {"type": {"const": "created"}, "at": {"type": "string"}}
+    },
+    "deleted": {
+      "title": "Deleted",
+      "type": "object",
+      "properties": {"type": {"enum": ["deleted"]}, "at": {"type": "string"}}
+    }
+  },
+  "oneOf": [
+    {"$ref": "#/$defs/created"},
+    {"$ref": "#/$defs/deleted"},
+    {"type": "object", "properties": {"type": {"const": "moved"}}}
+  ]
+}`
+
+func TestFindUnion(t *testing.T) {
+	s := compileString(t, unionSchema)
+	union := FindUnion(s, nil)
+	if assert.NotNil(t, union) {
+		assert.Equal(t, "type", union.Property)
+		assert.Len(t, union.Variants, 3)
+		assert.Equal(t, `"created"`, union.Variants[0].Value)
+		assert.Equal(t, "Created", union.Variants[0].Name)
+		assert.Equal(t, `"deleted"`, union.Variants[1].Value)
+		assert.Equal(t, `"moved"`, union.Variants[2].Value)
+		assert.Equal(t, "ItemType[2]", union.Variants[2].Name)
+	}
+
+	// the values must be distinct
+	s = compileString(t, `{"anyOf": [
+		{"properties": {"kind": {"const": "a"}}},
+		{"properties": {"kind": {"const": "a"}}}
+	]}`)
+	assert.Nil(t, FindUnion(s, nil))
+
+	// every branch must declare the property
+	s = compileString(t, `{"oneOf": [
+		{"properties": {"kind": {"const": "a"}}},
+		{"properties": {"other": {"const": "b"}}}
+	]}`)
+	assert.Nil(t, FindUnion(s, nil))
+}
+
+func TestFindUnion_OpenAPI(t *testing.T) {
+	s := compileString(t, `{
+		"$defs": {
+			"Dog": {"type": "object", "properties": {"pet": {"type": "string"}}},
+			"Cat": {"type": "object", "properties": {"pet": {"type": "string"}}},
+			"Bird": {"type": "object", "properties": {"pet": {"type": "string"}}}
+		},
+		"oneOf": [{"$ref": "#/$defs/Dog"}, {"$ref": "#/$defs/Cat"}, {"$ref": "#/$defs/Bird"}]
+	}`)
+	raw := RawSchema{"discriminator": map[string]interface{}{
+		"propertyName": "pet",
+		"mapping":      map[string]interface{}{"dog": "#/$defs/Dog", "cat": "Cat"},
+	}}
+
+	union := FindUnion(s, raw)
+	if assert.NotNil(t, union) {
+		assert.Equal(t, "pet", union.Property)
+		var values []string
+		for _, v := range union.Variants {
+			values = append(values, v.Value)
+		}
+		assert.Equal(t, []string{`"dog"`, `"cat"`, `"Bird"`}, values)
+	}
+}
+
+func TestConverter_ConvertUnion(t *testing.T) {
+	writeSchema(t, "/union/event.schema.json", unionSchema)
+	cfg := config
+	cfg.Destination = "/union-output"
+	assert.Nil(t, NewConverter(cfg).Convert("/union"))
+
+	page := readTree(t, cfg.Destination)["event-schema/_index.md"]
+	assert.Contains(t, page, "**Variants:** selected by the value of `type`")
+	assert.Contains(t, page, "| `\"created\"` | [Created](")
+	assert.Contains(t, page, "| `\"moved\"` | ItemType[2] |")
+}
+
+func TestConverter_ConvertUnionKinds(t *testing.T) {
+	// the variants of a oneOf are rendered below its own table, alongside an allOf, while
+	// their values are escaped for the table cells
+	writeSchema(t, "/union-kinds/shape.schema.json", `{
+  "title": "Shape",
+  "allOf": [{"type": "object"}],
+  "oneOf": [
+    {"title": "Square", "type": "object", "properties": {"kind": {"const": "a|b"}}},
+    {"title": "Circle", "type": "object", "properties": {"kind": {"const": "c`+"`"+`d"}}}
+  ]
+}`)
+	writeSchema(t, "/union-kinds/mark.schema.json", `{
+  "title": "Mark",
+  "anyOf": [
+    {"title": "Square", "type": "object", "properties": {"kind": {"const": "a|b"}}},
+    {"title": "Circle", "type": "object", "properties": {"kind": {"const": "c`+"`"+`d"}}}
+  ]
+}`)
+	cfg := config
+	cfg.Destination = "/union-kinds-output"
+	assert.Nil(t, NewConverter(cfg).Convert("/union-kinds"))
+
+	files := readTree(t, cfg.Destination)
+	shape := files["shape-schema/_index.md"]
+	assert.Contains(t, shape, "**AllOf:**")
+	assert.Contains(t, shape, "**OneOf:**")
+	assert.Contains(t, shape, "**Variants:** selected by the value of `kind`")
+
+	page := files["mark-schema/_index.md"]
+	assert.Contains(t, page, "**Variants:** selected by the value of `kind`")
+	assert.Contains(t, page, "| `\"a\\|b\"` | Square |")
+	assert.Contains(t, page, "| ``\"c`d\"`` | Circle |")
+}